@@ -18,6 +18,7 @@ package metadata
 import (
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras/cmd/oras/internal/option"
+	"oras.land/oras/internal/speed"
 )
 
 // PushHandler handles metadata output for push events.
@@ -25,7 +26,12 @@ type PushHandler interface {
 	TaggedHandler
 
 	OnCopied(opts *option.Target) error
-	OnCompleted(root ocispec.Descriptor) error
+	// OnSummary is called with the summary of the uploaded blobs before
+	// OnCompleted, if the speed report is requested.
+	OnSummary(summary speed.Summary) error
+	// OnCompleted is called after the push is completed, with the layers of
+	// the pushed manifest.
+	OnCompleted(root ocispec.Descriptor, layers []ocispec.Descriptor) error
 }
 
 // AttachHandler handles metadata output for attach events.
@@ -54,8 +60,9 @@ type ManifestFetchHandler interface {
 type PullHandler interface {
 	// OnLayerSkipped is called when a layer is skipped.
 	OnLayerSkipped(ocispec.Descriptor) error
-	// OnFilePulled is called after a file is pulled.
-	OnFilePulled(name string, outputDir string, desc ocispec.Descriptor, descPath string) error
+	// OnFilePulled is called after a file is pulled. cached is true if the
+	// file is restored from the local cache.
+	OnFilePulled(name string, outputDir string, desc ocispec.Descriptor, descPath string, cached bool) error
 	// OnCompleted is called when the pull cmd execution is completed.
 	OnCompleted(opts *option.Target, desc ocispec.Descriptor) error
 }
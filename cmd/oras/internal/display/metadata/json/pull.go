@@ -46,8 +46,8 @@ func NewPullHandler(out io.Writer, path string) metadata.PullHandler {
 }
 
 // OnFilePulled implements metadata.PullHandler.
-func (ph *PullHandler) OnFilePulled(name string, outputDir string, desc ocispec.Descriptor, descPath string) error {
-	return ph.pulled.Add(name, outputDir, desc, descPath)
+func (ph *PullHandler) OnFilePulled(name string, outputDir string, desc ocispec.Descriptor, descPath string, cached bool) error {
+	return ph.pulled.Add(name, outputDir, desc, descPath, cached)
 }
 
 // OnCompleted implements metadata.PullHandler.
@@ -24,13 +24,15 @@ import (
 	"oras.land/oras/cmd/oras/internal/option"
 	"oras.land/oras/cmd/oras/internal/output"
 	"oras.land/oras/internal/contentutil"
+	"oras.land/oras/internal/speed"
 )
 
 // PushHandler handles JSON metadata output for push events.
 type PushHandler struct {
-	path   string
-	out    io.Writer
-	tagged model.Tagged
+	path    string
+	out     io.Writer
+	tagged  model.Tagged
+	summary *speed.Summary
 }
 
 // NewPushHandler creates a new handler for push events.
@@ -55,7 +57,13 @@ func (ph *PushHandler) OnCopied(opts *option.Target) error {
 	return nil
 }
 
+// OnSummary is called with the summary of the uploaded blobs.
+func (ph *PushHandler) OnSummary(summary speed.Summary) error {
+	ph.summary = &summary
+	return nil
+}
+
 // OnCompleted is called after the push is completed.
-func (ph *PushHandler) OnCompleted(root ocispec.Descriptor) error {
-	return output.PrintPrettyJSON(ph.out, model.NewPush(root, ph.path, ph.tagged.Tags()))
+func (ph *PushHandler) OnCompleted(root ocispec.Descriptor, layers []ocispec.Descriptor) error {
+	return output.PrintPrettyJSON(ph.out, model.NewPush(root, ph.path, ph.tagged.Tags(), layers, ph.summary))
 }
@@ -30,10 +30,12 @@ type File struct {
 	// Path is the absolute path of the pulled file.
 	Path string `json:"path"`
 	Descriptor
+	// Cached is true if the file is restored from the local cache.
+	Cached bool `json:"cached,omitempty"`
 }
 
 // newFile creates a new file metadata.
-func newFile(name string, outputDir string, desc ocispec.Descriptor, descPath string) (File, error) {
+func newFile(name string, outputDir string, desc ocispec.Descriptor, descPath string, cached bool) (File, error) {
 	path := name
 	if !filepath.IsAbs(name) {
 		var err error
@@ -51,6 +53,7 @@ func newFile(name string, outputDir string, desc ocispec.Descriptor, descPath st
 	return File{
 		Path:       path,
 		Descriptor: FromDescriptor(descPath, desc),
+		Cached:     cached,
 	}, nil
 }
 
@@ -83,10 +86,10 @@ func (p *Pulled) Files() []File {
 }
 
 // Add adds a pulled file.
-func (p *Pulled) Add(name string, outputDir string, desc ocispec.Descriptor, descPath string) error {
+func (p *Pulled) Add(name string, outputDir string, desc ocispec.Descriptor, descPath string, cached bool) error {
 	p.lock.Lock()
 	defer p.lock.Unlock()
-	file, err := newFile(name, outputDir, desc, descPath)
+	file, err := newFile(name, outputDir, desc, descPath, cached)
 	if err != nil {
 		return err
 	}
@@ -17,22 +17,47 @@ package model
 
 import (
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras/internal/speed"
 )
 
 // push contains metadata formatted by oras push.
 type push struct {
 	Descriptor
-	ReferenceAsTags []string `json:"referenceAsTags"`
+	ReferenceAsTags []string     `json:"referenceAsTags"`
+	Layers          []Descriptor `json:"layers"`
+	Summary         *pushSummary `json:"summary,omitempty"`
+}
+
+// pushSummary summarizes the blobs uploaded by oras push.
+type pushSummary struct {
+	UploadedBytes  int64   `json:"uploadedBytes"`
+	SkippedBytes   int64   `json:"skippedBytes"`
+	ElapsedSeconds float64 `json:"elapsedSeconds"`
+	BytesPerSecond float64 `json:"bytesPerSecond"`
 }
 
 // NewPush returns a metadata getter for push command.
-func NewPush(desc ocispec.Descriptor, path string, tags []string) any {
+func NewPush(desc ocispec.Descriptor, path string, tags []string, layers []ocispec.Descriptor, summary *speed.Summary) any {
 	var refAsTags []string
 	for _, tag := range tags {
 		refAsTags = append(refAsTags, path+":"+tag)
 	}
-	return push{
+	pushedLayers := make([]Descriptor, 0, len(layers))
+	for _, layer := range layers {
+		pushedLayers = append(pushedLayers, FromDescriptor(path, layer))
+	}
+	ret := push{
 		Descriptor:      FromDescriptor(path, desc),
 		ReferenceAsTags: refAsTags,
+		Layers:          pushedLayers,
+	}
+	if summary != nil {
+		ret.Summary = &pushSummary{
+			UploadedBytes:  summary.CopiedBytes,
+			SkippedBytes:   summary.SkippedBytes,
+			ElapsedSeconds: summary.Elapsed.Seconds(),
+			BytesPerSecond: summary.BytesPerSecond(),
+		}
 	}
+	return ret
 }
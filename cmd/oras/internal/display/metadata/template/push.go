@@ -24,6 +24,7 @@ import (
 	"oras.land/oras/cmd/oras/internal/option"
 	"oras.land/oras/cmd/oras/internal/output"
 	"oras.land/oras/internal/contentutil"
+	"oras.land/oras/internal/speed"
 )
 
 // PushHandler handles go-template metadata output for push events.
@@ -31,6 +32,7 @@ type PushHandler struct {
 	template string
 	path     string
 	tagged   model.Tagged
+	summary  *speed.Summary
 	out      io.Writer
 }
 
@@ -57,7 +59,13 @@ func (ph *PushHandler) OnCopied(opts *option.Target) error {
 	return nil
 }
 
+// OnSummary is called with the summary of the uploaded blobs.
+func (ph *PushHandler) OnSummary(summary speed.Summary) error {
+	ph.summary = &summary
+	return nil
+}
+
 // OnCompleted is called after the push is completed.
-func (ph *PushHandler) OnCompleted(root ocispec.Descriptor) error {
-	return output.ParseAndWrite(ph.out, model.NewPush(root, ph.path, ph.tagged.Tags()), ph.template)
+func (ph *PushHandler) OnCompleted(root ocispec.Descriptor, layers []ocispec.Descriptor) error {
+	return output.ParseAndWrite(ph.out, model.NewPush(root, ph.path, ph.tagged.Tags(), layers, ph.summary), ph.template)
 }
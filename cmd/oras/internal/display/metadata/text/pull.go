@@ -42,7 +42,7 @@ func (ph *PullHandler) OnCompleted(opts *option.Target, desc ocispec.Descriptor)
 	return nil
 }
 
-func (ph *PullHandler) OnFilePulled(_ string, _ string, _ ocispec.Descriptor, _ string) error {
+func (ph *PullHandler) OnFilePulled(_ string, _ string, _ ocispec.Descriptor, _ string, _ bool) error {
 	return nil
 }
 
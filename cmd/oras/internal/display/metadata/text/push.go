@@ -22,6 +22,7 @@ import (
 	"oras.land/oras/cmd/oras/internal/display/metadata"
 	"oras.land/oras/cmd/oras/internal/option"
 	"oras.land/oras/cmd/oras/internal/output"
+	"oras.land/oras/internal/speed"
 )
 
 // PushHandler handles text metadata output for push events.
@@ -49,8 +50,14 @@ func (h *PushHandler) OnCopied(opts *option.Target) error {
 	return h.printer.Println("Pushed", opts.AnnotatedReference())
 }
 
+// OnSummary is called with the summary of the uploaded blobs, which is
+// printed with the speed report instead.
+func (h *PushHandler) OnSummary(speed.Summary) error {
+	return nil
+}
+
 // OnCompleted is called after the push is completed.
-func (h *PushHandler) OnCompleted(root ocispec.Descriptor) error {
+func (h *PushHandler) OnCompleted(root ocispec.Descriptor, _ []ocispec.Descriptor) error {
 	err := h.printer.Println("ArtifactType:", root.ArtifactType)
 	if err != nil {
 		return err
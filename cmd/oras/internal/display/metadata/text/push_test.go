@@ -69,7 +69,7 @@ func TestPushHandler_OnCompleted(t *testing.T) {
 			p := &PushHandler{
 				printer: printer,
 			}
-			if err := p.OnCompleted(tt.root); (err != nil) != tt.wantErr {
+			if err := p.OnCompleted(tt.root, nil); (err != nil) != tt.wantErr {
 				t.Errorf("PushHandler.OnCompleted() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
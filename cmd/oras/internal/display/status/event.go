@@ -0,0 +1,242 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+)
+
+// States of the push and pull events.
+const (
+	EventStateQueued      = "queued"
+	EventStateUploading   = "uploading"
+	EventStateExists      = "exists"
+	EventStateMounted     = "mounted"
+	EventStateDownloading = "downloading"
+	EventStateProcessing  = "processing"
+	EventStateRestored    = "restored"
+	EventStateSkipped     = "skipped"
+	EventStateDone        = "done"
+	EventStateFailed      = "failed"
+)
+
+// eventProgressInterval is the minimum interval between the progress events
+// of a node being transferred.
+const eventProgressInterval = time.Second
+
+// Event is a status event of a pushed or pulled file or node.
+type Event struct {
+	State       string `json:"state"`
+	Name        string `json:"name,omitempty"`
+	Digest      string `json:"digest,omitempty"`
+	MediaType   string `json:"mediaType,omitempty"`
+	Size        int64  `json:"size"`
+	Transferred int64  `json:"transferred"`
+	Error       string `json:"error,omitempty"`
+}
+
+// eventEmitter writes events as JSON lines and counts the bytes transferred
+// for each node, reported periodically in progress events.
+type eventEmitter struct {
+	lock    sync.Mutex
+	encoder *json.Encoder
+	// transferred maps digests to the number of bytes transferred.
+	transferred sync.Map
+	// progressState is the state of the progress events.
+	progressState string
+	// interval is the minimum interval between the progress events of a
+	// node.
+	interval time.Duration
+}
+
+// EventPushHandler handles status output for push events as JSON lines.
+type EventPushHandler struct {
+	eventEmitter
+}
+
+// NewEventPushHandler returns a new handler writing push events to out, one
+// JSON object per line.
+func NewEventPushHandler(out io.Writer) PushHandler {
+	return &EventPushHandler{
+		eventEmitter: eventEmitter{
+			encoder:       json.NewEncoder(out),
+			progressState: EventStateUploading,
+			interval:      eventProgressInterval,
+		},
+	}
+}
+
+// OnFileLoading is called before loading a file.
+func (ph *EventPushHandler) OnFileLoading(name string) error {
+	return ph.emit(Event{State: EventStateQueued, Name: name})
+}
+
+// OnEmptyArtifact is called when no file is loaded for an artifact push.
+func (ph *EventPushHandler) OnEmptyArtifact() error {
+	return nil
+}
+
+// TrackTarget returns a target counting the transferred bytes.
+func (ph *EventPushHandler) TrackTarget(gt oras.GraphTarget) (oras.GraphTarget, StopTrackTargetFunc, error) {
+	return &eventTarget{GraphTarget: gt, emitter: &ph.eventEmitter}, discardStopTrack, nil
+}
+
+// UpdateCopyOptions adds status events to the copy options.
+func (ph *EventPushHandler) UpdateCopyOptions(opts *oras.CopyGraphOptions, _ content.Fetcher) {
+	opts.OnCopySkipped = func(ctx context.Context, desc ocispec.Descriptor) error {
+		return ph.emit(newEvent(EventStateExists, desc, 0))
+	}
+	opts.PreCopy = func(ctx context.Context, desc ocispec.Descriptor) error {
+		return ph.emit(newEvent(EventStateUploading, desc, 0))
+	}
+	opts.PostCopy = func(ctx context.Context, desc ocispec.Descriptor) error {
+		return ph.emit(newEvent(EventStateDone, desc, ph.counter(desc).Load()))
+	}
+	opts.OnMounted = func(ctx context.Context, desc ocispec.Descriptor) error {
+		return ph.emit(newEvent(EventStateMounted, desc, 0))
+	}
+}
+
+// EventPullHandler handles status output for pull events as JSON lines.
+type EventPullHandler struct {
+	eventEmitter
+}
+
+// NewEventPullHandler returns a new handler writing pull events to out, one
+// JSON object per line.
+func NewEventPullHandler(out io.Writer) PullHandler {
+	return &EventPullHandler{
+		eventEmitter: eventEmitter{
+			encoder:       json.NewEncoder(out),
+			progressState: EventStateDownloading,
+			interval:      eventProgressInterval,
+		},
+	}
+}
+
+// TrackTarget returns a target counting the transferred bytes.
+func (ph *EventPullHandler) TrackTarget(gt oras.GraphTarget) (oras.GraphTarget, StopTrackTargetFunc, error) {
+	return &eventTarget{GraphTarget: gt, emitter: &ph.eventEmitter}, discardStopTrack, nil
+}
+
+// OnNodeProcessing implements PullHandler.
+func (ph *EventPullHandler) OnNodeProcessing(desc ocispec.Descriptor) error {
+	return ph.emit(newEvent(EventStateProcessing, desc, 0))
+}
+
+// OnNodeDownloading implements PullHandler.
+func (ph *EventPullHandler) OnNodeDownloading(desc ocispec.Descriptor) error {
+	return ph.emit(newEvent(EventStateDownloading, desc, 0))
+}
+
+// OnNodeDownloaded implements PullHandler.
+func (ph *EventPullHandler) OnNodeDownloaded(desc ocispec.Descriptor) error {
+	return ph.emit(newEvent(EventStateDone, desc, ph.counter(desc).Load()))
+}
+
+// OnNodeRestored implements PullHandler.
+func (ph *EventPullHandler) OnNodeRestored(desc ocispec.Descriptor) error {
+	return ph.emit(newEvent(EventStateRestored, desc, 0))
+}
+
+// OnNodeSkipped implements PullHandler.
+func (ph *EventPullHandler) OnNodeSkipped(desc ocispec.Descriptor) error {
+	return ph.emit(newEvent(EventStateSkipped, desc, 0))
+}
+
+func (e *eventEmitter) emit(event Event) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.encoder.Encode(event)
+}
+
+func (e *eventEmitter) counter(desc ocispec.Descriptor) *atomic.Int64 {
+	counter, _ := e.transferred.LoadOrStore(desc.Digest.String(), &atomic.Int64{})
+	return counter.(*atomic.Int64)
+}
+
+func newEvent(state string, desc ocispec.Descriptor, transferred int64) Event {
+	return Event{
+		State:       state,
+		Name:        desc.Annotations[ocispec.AnnotationTitle],
+		Digest:      desc.Digest.String(),
+		MediaType:   desc.MediaType,
+		Size:        desc.Size,
+		Transferred: transferred,
+	}
+}
+
+// eventTarget counts the bytes pushed to the target and reports failures.
+type eventTarget struct {
+	oras.GraphTarget
+	emitter *eventEmitter
+}
+
+// Push pushes the content while counting the transferred bytes.
+func (t *eventTarget) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	counter := t.emitter.counter(expected)
+	cr := &countingReader{
+		Reader:   r,
+		counter:  counter,
+		emitter:  t.emitter,
+		desc:     expected,
+		reported: time.Now(),
+	}
+	if err := t.GraphTarget.Push(ctx, expected, cr); err != nil {
+		e := newEvent(EventStateFailed, expected, counter.Load())
+		e.Error = err.Error()
+		_ = t.emitter.emit(e)
+		return err
+	}
+	return nil
+}
+
+// Mount mounts a blob from a specified repository. This method is invoked only
+// by the `*remote.Repository` target.
+func (t *eventTarget) Mount(ctx context.Context, desc ocispec.Descriptor, fromRepo string, getContent func() (io.ReadCloser, error)) error {
+	mounter := t.GraphTarget.(registry.Mounter)
+	return mounter.Mount(ctx, desc, fromRepo, getContent)
+}
+
+// countingReader counts the bytes read of desc, emitting a progress event at
+// most once per interval of the emitter.
+type countingReader struct {
+	io.Reader
+	counter  *atomic.Int64
+	emitter  *eventEmitter
+	desc     ocispec.Descriptor
+	reported time.Time
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	transferred := r.counter.Add(int64(n))
+	if now := time.Now(); n > 0 && now.Sub(r.reported) >= r.emitter.interval {
+		r.reported = now
+		_ = r.emitter.emit(newEvent(r.emitter.progressState, r.desc, transferred))
+	}
+	return n, err
+}
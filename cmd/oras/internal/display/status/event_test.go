@@ -0,0 +1,156 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+type failingPushTarget struct {
+	oras.GraphTarget
+}
+
+func (t *failingPushTarget) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	if _, err := io.CopyN(io.Discard, r, 1); err != nil {
+		return err
+	}
+	return errors.New("push failed")
+}
+
+func TestEventPushHandler(t *testing.T) {
+	ctx := context.Background()
+	var out bytes.Buffer
+	ph := NewEventPushHandler(&out)
+	ph.(*EventPushHandler).interval = 0
+	blob := []byte("hello")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	desc.Annotations = map[string]string{ocispec.AnnotationTitle: "hello.txt"}
+
+	if err := ph.OnFileLoading("hello.txt"); err != nil {
+		t.Fatal(err)
+	}
+	var opts oras.CopyGraphOptions
+	ph.UpdateCopyOptions(&opts, nil)
+	dst, _, err := ph.TrackTarget(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := opts.PreCopy(ctx, desc); err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal(err)
+	}
+	if err := opts.PostCopy(ctx, desc); err != nil {
+		t.Fatal(err)
+	}
+	if err := opts.OnCopySkipped(ctx, desc); err != nil {
+		t.Fatal(err)
+	}
+	failingHandler := NewEventPushHandler(&out)
+	failingHandler.(*EventPushHandler).interval = 0
+	failing, _, err := failingHandler.TrackTarget(&failingPushTarget{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := failing.Push(ctx, desc, bytes.NewReader(blob)); err == nil {
+		t.Fatal("Push() error = nil, want error")
+	}
+
+	var got []Event
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var e Event
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("invalid event %q: %v", line, err)
+		}
+		got = append(got, e)
+	}
+	event := func(state string, transferred int64) Event {
+		return Event{State: state, Name: "hello.txt", Digest: desc.Digest.String(), MediaType: "test", Size: 5, Transferred: transferred}
+	}
+	failed := event(EventStateFailed, 1)
+	failed.Error = "push failed"
+	want := []Event{
+		{State: EventStateQueued, Name: "hello.txt"},
+		event(EventStateUploading, 0),
+		event(EventStateUploading, 5),
+		event(EventStateDone, 5),
+		event(EventStateExists, 0),
+		event(EventStateUploading, 1),
+		failed,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+}
+
+func TestEventPullHandler(t *testing.T) {
+	ctx := context.Background()
+	var out bytes.Buffer
+	ph := NewEventPullHandler(&out)
+	ph.(*EventPullHandler).interval = 0
+	blob := []byte("hello")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	desc.Annotations = map[string]string{ocispec.AnnotationTitle: "hello.txt"}
+
+	dst, _, err := ph.TrackTarget(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ph.OnNodeDownloading(desc); err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal(err)
+	}
+	for _, notify := range []func(ocispec.Descriptor) error{ph.OnNodeDownloaded, ph.OnNodeProcessing, ph.OnNodeRestored, ph.OnNodeSkipped} {
+		if err := notify(desc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got []string
+	var transferred int64
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var e Event
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("invalid event %q: %v", line, err)
+		}
+		got = append(got, e.State)
+		if e.State == EventStateDone {
+			transferred = e.Transferred
+		}
+	}
+	want := []string{EventStateDownloading, EventStateDownloading, EventStateDone, EventStateProcessing, EventStateRestored, EventStateSkipped}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("states = %v, want %v", got, want)
+	}
+	if transferred != int64(len(blob)) {
+		t.Errorf("transferred = %d, want %d", transferred, len(blob))
+	}
+}
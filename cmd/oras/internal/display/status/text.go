@@ -70,6 +70,10 @@ func (ph *TextPushHandler) UpdateCopyOptions(opts *oras.CopyGraphOptions, fetche
 		}
 		return ph.printer.PrintStatus(desc, PushPromptUploaded)
 	}
+	opts.OnMounted = func(ctx context.Context, desc ocispec.Descriptor) error {
+		committed.Store(desc.Digest.String(), desc.Annotations[ocispec.AnnotationTitle])
+		return ph.printer.PrintStatus(desc, PushPromptMounted)
+	}
 }
 
 // NewTextAttachHandler returns a new handler for attach command.
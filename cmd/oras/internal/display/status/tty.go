@@ -74,6 +74,10 @@ func (ph *TTYPushHandler) UpdateCopyOptions(opts *oras.CopyGraphOptions, fetcher
 			return ph.tracked.Prompt(d, PushPromptSkipped)
 		})
 	}
+	opts.OnMounted = func(ctx context.Context, desc ocispec.Descriptor) error {
+		committed.Store(desc.Digest.String(), desc.Annotations[ocispec.AnnotationTitle])
+		return ph.tracked.Prompt(desc, PushPromptMounted)
+	}
 }
 
 // NewTTYAttachHandler returns a new handler for attach status events.
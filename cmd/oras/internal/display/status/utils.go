@@ -31,4 +31,5 @@ const (
 	PushPromptUploading = "Uploading"
 	PushPromptSkipped   = "Skipped  "
 	PushPromptExists    = "Exists   "
+	PushPromptMounted   = "Mounted  "
 )
@@ -16,26 +16,40 @@ limitations under the License.
 package option
 
 import (
+	"fmt"
 	"os"
+	"strconv"
 
 	"oras.land/oras-go/v2"
-	"oras.land/oras-go/v2/content/oci"
 	"oras.land/oras/internal/cache"
 )
 
 type Cache struct {
-	Root string
+	Root     string
+	MaxBytes int64
 }
 
 // CachedTarget gets the target storage with caching if cache root is specified.
+// The least recently used cached blobs are evicted beforehand to keep the cache
+// under ORAS_CACHE_MAX_BYTES if specified.
 func (opts *Cache) CachedTarget(src oras.ReadOnlyTarget) (oras.ReadOnlyTarget, error) {
 	opts.Root = os.Getenv("ORAS_CACHE")
 	if opts.Root != "" {
-		ociStore, err := oci.New(opts.Root)
+		if value := os.Getenv("ORAS_CACHE_MAX_BYTES"); value != "" {
+			maxBytes, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || maxBytes < 0 {
+				return nil, fmt.Errorf("invalid value %q for ORAS_CACHE_MAX_BYTES: must be a non-negative number of bytes", value)
+			}
+			opts.MaxBytes = maxBytes
+		}
+		store, err := cache.NewStore(opts.Root, opts.MaxBytes)
 		if err != nil {
 			return nil, err
 		}
-		return cache.New(src, ociStore), nil
+		if err := store.GC(); err != nil {
+			return nil, fmt.Errorf("failed to evict blobs from the cache %s: %w", opts.Root, err)
+		}
+		return cache.New(src, store), nil
 	}
 	return src, nil
 }
@@ -22,7 +22,6 @@ import (
 
 	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/content/memory"
-	"oras.land/oras-go/v2/content/oci"
 	"oras.land/oras/internal/cache"
 )
 
@@ -34,11 +33,11 @@ func TestCache_CachedTarget(t *testing.T) {
 	defer os.Unsetenv("ORAS_CACHE")
 	opts := Cache{}
 
-	ociStore, err := oci.New(tempDir)
+	store, err := cache.NewStore(tempDir, 0)
 	if err != nil {
-		t.Fatal("error calling oci.New(), error =", err)
+		t.Fatal("error calling cache.NewStore(), error =", err)
 	}
-	want := cache.New(mockTarget, ociStore)
+	want := cache.New(mockTarget, store)
 
 	got, err := opts.CachedTarget(mockTarget)
 	if err != nil {
@@ -49,6 +48,18 @@ func TestCache_CachedTarget(t *testing.T) {
 	}
 }
 
+func TestCache_CachedTarget_invalidMaxBytes(t *testing.T) {
+	os.Setenv("ORAS_CACHE", t.TempDir())
+	defer os.Unsetenv("ORAS_CACHE")
+	os.Setenv("ORAS_CACHE_MAX_BYTES", "-1")
+	defer os.Unsetenv("ORAS_CACHE_MAX_BYTES")
+	opts := Cache{}
+
+	if _, err := opts.CachedTarget(mockTarget); err == nil {
+		t.Fatal("Cache.CachedTarget() error = nil, want error")
+	}
+}
+
 func TestCache_CachedTarget_emptyRoot(t *testing.T) {
 	os.Setenv("ORAS_CACHE", "")
 	opts := Cache{}
@@ -60,3 +60,25 @@ func (opts *Confirmation) AskForConfirmation(r io.Reader, prompt string) (bool,
 		return false, nil
 	}
 }
+
+// AskForTypedConfirmation prints a prompt to ask the user to type expected
+// before doing a dangerous action. Unlike AskForConfirmation, it cannot be
+// skipped by the force flag.
+func (opts *Confirmation) AskForTypedConfirmation(r io.Reader, prompt string, expected string) (bool, error) {
+	fmt.Print(prompt, " ")
+
+	var response string
+	scanner := bufio.NewScanner(r)
+	if ok := scanner.Scan(); ok {
+		response = scanner.Text()
+	}
+	if err := scanner.Err(); err != nil {
+		return false, err
+	}
+
+	if strings.TrimSpace(response) == expected {
+		return true, nil
+	}
+	fmt.Println("Operation cancelled.")
+	return false, nil
+}
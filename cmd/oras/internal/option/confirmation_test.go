@@ -69,3 +69,27 @@ func TestConfirmation_AskForConfirmation_manuallyConfirmed(t *testing.T) {
 		t.Fatalf("Confirmation.AskForConfirmation() got %v, want %v", got, false)
 	}
 }
+
+func TestConfirmation_AskForTypedConfirmation(t *testing.T) {
+	opts := Confirmation{
+		Force: true,
+	}
+
+	r := strings.NewReader("example\n")
+	got, err := opts.AskForTypedConfirmation(r, "", "example")
+	if err != nil {
+		t.Fatal("Confirmation.AskForTypedConfirmation() error =", err)
+	}
+	if !reflect.DeepEqual(got, true) {
+		t.Fatalf("Confirmation.AskForTypedConfirmation() got %v, want %v", got, true)
+	}
+
+	r = strings.NewReader("y")
+	got, err = opts.AskForTypedConfirmation(r, "", "example")
+	if err != nil {
+		t.Fatal("Confirmation.AskForTypedConfirmation() error =", err)
+	}
+	if !reflect.DeepEqual(got, false) {
+		t.Fatalf("Confirmation.AskForTypedConfirmation() got %v, want %v", got, false)
+	}
+}
@@ -21,13 +21,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -56,6 +59,22 @@ const (
 	identityTokenFromStdinFlag = "identity-token-stdin"
 )
 
+// Parameters of the default retry policy, which are the same as
+// retry.DefaultPolicy except that the backoff starts from the minimum wait.
+const (
+	defaultMaxRetries   = 5
+	defaultRetryMinWait = 200 * time.Millisecond
+	defaultRetryMaxWait = 3 * time.Second
+)
+
+// retryFlagAliases maps the alternative names of the retry flags to the
+// flags they stand for.
+var retryFlagAliases = map[string]string{
+	"retries":               "max-retries",
+	"retry-initial-backoff": "retry-min-wait",
+	"retry-max-backoff":     "retry-max-wait",
+}
+
 // Remote options struct contains flags and arguments specifying one registry.
 // Remote implements oerrors.Handler and interface.
 type Remote struct {
@@ -69,9 +88,25 @@ type Remote struct {
 	secretFromStdin bool
 	Secret          string
 	flagPrefix      string
+	// RequestsPerSecond limits the rate of HTTP requests sent to the registry
+	// if positive.
+	RequestsPerSecond float64
+	// WrapTransport wraps the transport sending each HTTP request to the
+	// registry, if set.
+	WrapTransport func(http.RoundTripper) http.RoundTripper
+	// BlobTimeout is the maximum duration of a blob transfer before it is
+	// cancelled and retried, unlimited if 0.
+	BlobTimeout time.Duration
+	// MaxMetadataBytes limits the size of the manifests fetched from the
+	// registry if positive.
+	MaxMetadataBytes int64
 
 	resolveFlag           []string
 	applyDistributionSpec bool
+	applyRetry            bool
+	maxRetries            int
+	retryMinWait          time.Duration
+	retryMaxWait          time.Duration
 	headerFlags           []string
 	headers               http.Header
 	warned                map[string]*sync.Map
@@ -84,6 +119,11 @@ func (opts *Remote) EnableDistributionSpecFlag() {
 	opts.applyDistributionSpec = true
 }
 
+// EnableRetryFlags set the flags tuning the retry policy as applicable.
+func (opts *Remote) EnableRetryFlags() {
+	opts.applyRetry = true
+}
+
 // ApplyFlags applies flags to a command flag set.
 func (opts *Remote) ApplyFlags(fs *pflag.FlagSet) {
 	opts.ApplyFlagsWithPrefix(fs, "", "")
@@ -131,6 +171,54 @@ func (opts *Remote) ApplyFlagsWithPrefix(fs *pflag.FlagSet, prefix, description
 	fs.StringArrayVar(&opts.resolveFlag, opts.flagPrefix+"resolve", nil, "customized DNS for "+notePrefix+"registry, formatted in `host:port:address[:address_port]`")
 	fs.StringArrayVar(&opts.Configs, opts.flagPrefix+"registry-config", nil, "`path` of the authentication file for "+notePrefix+"registry")
 	fs.StringArrayVarP(&opts.headerFlags, opts.flagPrefix+"header", shortHeader, nil, "add custom headers to "+notePrefix+"requests")
+	fs.Int64Var(&opts.MaxMetadataBytes, opts.flagPrefix+"max-metadata-bytes", 0, "[Preview] maximum size in `bytes` of the manifests and configs handled for the "+notePrefix+"registry, 4 MiB if 0")
+	if opts.applyRetry {
+		fs.IntVar(&opts.maxRetries, opts.flagPrefix+"max-retries", defaultMaxRetries, "[Preview] maximum number of retries of a failed request to the "+notePrefix+"registry on 5xx, 429 or timeout errors, alias --"+opts.flagPrefix+"retries")
+		fs.DurationVar(&opts.retryMinWait, opts.flagPrefix+"retry-min-wait", defaultRetryMinWait, "[Preview] initial wait `duration` before retrying a failed request to the "+notePrefix+"registry, doubled on each retry up to --"+opts.flagPrefix+"retry-max-wait, alias --"+opts.flagPrefix+"retry-initial-backoff")
+		fs.DurationVar(&opts.retryMaxWait, opts.flagPrefix+"retry-max-wait", defaultRetryMaxWait, "[Preview] maximum wait `duration` between retries of a failed request to the "+notePrefix+"registry, with exponential backoff and jitter, alias --"+opts.flagPrefix+"retry-max-backoff")
+		fs.DurationVar(&opts.BlobTimeout, opts.flagPrefix+"blob-timeout", 0, "[Preview] maximum `duration` of a blob transfer with the "+notePrefix+"registry before it is cancelled and retried up to --"+opts.flagPrefix+"max-retries times, unlimited if 0")
+		// accept the alternative names in place of the retry flags
+		normalize := fs.GetNormalizeFunc()
+		fs.SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+			if rest, ok := strings.CutPrefix(name, opts.flagPrefix); ok {
+				if target, ok := retryFlagAliases[rest]; ok {
+					name = opts.flagPrefix + target
+				}
+			}
+			return normalize(f, name)
+		})
+	}
+}
+
+// ConnectionArgs returns the command-line flags connecting to the registry as
+// configured by opts. The credentials and the custom headers are left out
+// since they may hold secrets.
+func (opts *Remote) ConnectionArgs() []string {
+	var args []string
+	flag := func(name string) string {
+		return "--" + opts.flagPrefix + name
+	}
+	if opts.Insecure {
+		args = append(args, flag("insecure"))
+	}
+	if opts.plainHTTP != nil {
+		if plainHTTP, enforced := opts.plainHTTP(); enforced {
+			args = append(args, flag("plain-http")+"="+strconv.FormatBool(plainHTTP))
+		}
+	}
+	if opts.CACertFilePath != "" {
+		args = append(args, flag(caFileFlag), opts.CACertFilePath)
+	}
+	if opts.CertFilePath != "" {
+		args = append(args, flag(certFileFlag), opts.CertFilePath, flag(keyFileFlag), opts.KeyFilePath)
+	}
+	for _, resolve := range opts.resolveFlag {
+		args = append(args, flag("resolve"), resolve)
+	}
+	for _, config := range opts.Configs {
+		args = append(args, flag("registry-config"), config)
+	}
+	return args
 }
 
 // CheckStdinConflict checks if PasswordFromStdin or IdentityTokenFromStdin of a
@@ -163,6 +251,26 @@ func (opts *Remote) Parse(cmd *cobra.Command) error {
 	if err := oerrors.CheckMutuallyExclusiveFlags(cmd.Flags(), passwordAndIdTokenFlags...); err != nil {
 		return err
 	}
+	if opts.applyRetry {
+		if opts.maxRetries < 0 {
+			return fmt.Errorf("invalid value %d for --%smax-retries: must not be negative", opts.maxRetries, opts.flagPrefix)
+		}
+		if opts.retryMinWait <= 0 {
+			return fmt.Errorf("invalid value %v for --%sretry-min-wait: must be positive", opts.retryMinWait, opts.flagPrefix)
+		}
+		if opts.retryMaxWait <= 0 {
+			return fmt.Errorf("invalid value %v for --%sretry-max-wait: must be positive", opts.retryMaxWait, opts.flagPrefix)
+		}
+		if opts.retryMinWait > opts.retryMaxWait && cmd.Flags().Changed(opts.flagPrefix+"retry-min-wait") {
+			return fmt.Errorf("invalid value %v for --%sretry-min-wait: must not exceed --%sretry-max-wait %v", opts.retryMinWait, opts.flagPrefix, opts.flagPrefix, opts.retryMaxWait)
+		}
+		if opts.BlobTimeout < 0 {
+			return fmt.Errorf("invalid value %v for --%sblob-timeout: must not be negative", opts.BlobTimeout, opts.flagPrefix)
+		}
+	}
+	if opts.MaxMetadataBytes < 0 {
+		return fmt.Errorf("invalid value %d for --%smax-metadata-bytes: must not be negative", opts.MaxMetadataBytes, opts.flagPrefix)
+	}
 	if err := opts.parseCustomHeaders(); err != nil {
 		return err
 	}
@@ -252,6 +360,78 @@ func (opts *Remote) tlsConfig() (*tls.Config, error) {
 	return config, nil
 }
 
+// CheckMetadataSize returns an error wrapping errdef.ErrSizeExceedsLimit if
+// the manifest or config desc is larger than MaxMetadataBytes.
+func (opts *Remote) CheckMetadataSize(desc ocispec.Descriptor) error {
+	if opts.MaxMetadataBytes > 0 && desc.Size > opts.MaxMetadataBytes {
+		return &oerrors.Error{
+			Err:            fmt.Errorf("%s %s of size %d: %w: %d", desc.MediaType, desc.Digest, desc.Size, errdef.ErrSizeExceedsLimit, opts.MaxMetadataBytes),
+			Recommendation: fmt.Sprintf("Increase the limit via --%smax-metadata-bytes if the content is trusted", opts.flagPrefix),
+		}
+	}
+	return nil
+}
+
+// MaxRetries returns the maximum number of retries of a failed request.
+func (opts *Remote) MaxRetries() int {
+	if !opts.applyRetry {
+		return defaultMaxRetries
+	}
+	return opts.maxRetries
+}
+
+// retryPolicy returns the retry policy of the requests.
+func (opts *Remote) retryPolicy() retry.Policy {
+	if !opts.applyRetry {
+		return retry.DefaultPolicy
+	}
+	return &retryAfterPolicy{
+		Policy: &retry.GenericPolicy{
+			Retryable: retry.DefaultPredicate,
+			Backoff:   retry.ExponentialBackoff(opts.retryMinWait, 2, 0.1),
+			MinWait:   min(opts.retryMinWait, opts.retryMaxWait),
+			MaxWait:   opts.retryMaxWait,
+			MaxRetry:  opts.maxRetries,
+		},
+	}
+}
+
+// retryAfterPolicy is a retry.Policy waiting as long as the Retry-After header
+// of a 429 response asks, even beyond the maximum wait of the wrapped policy.
+type retryAfterPolicy struct {
+	retry.Policy
+}
+
+// Retry returns the duration to wait before retrying the request.
+func (p *retryAfterPolicy) Retry(attempt int, resp *http.Response, err error) (time.Duration, error) {
+	wait, err := p.Policy.Retry(attempt, resp, err)
+	if err != nil || wait < 0 || resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		return wait, err
+	}
+	if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After")); retryAfter > wait {
+		return retryAfter, nil
+	}
+	return wait, nil
+}
+
+// parseRetryAfter returns the duration requested by the value of a
+// Retry-After header, in seconds or as an HTTP date, or 0 if invalid.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds <= 0 || seconds > int64(math.MaxInt64/time.Second) {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
+
 // authClient assembles a oras auth client.
 func (opts *Remote) authClient(registry string, debug bool) (client *auth.Client, err error) {
 	config, err := opts.tlsConfig()
@@ -265,11 +445,22 @@ func (opts *Remote) authClient(registry string, debug bool) (client *auth.Client
 		return nil, err
 	}
 	baseTransport.DialContext = dialContext
+	var transport http.RoundTripper = baseTransport
+	if opts.WrapTransport != nil {
+		transport = opts.WrapTransport(transport)
+	}
+	if opts.RequestsPerSecond > 0 {
+		// limit retried requests as well
+		transport = onet.NewRateLimitedTransport(transport, opts.RequestsPerSecond)
+	}
 	client = &auth.Client{
 		Client: &http.Client{
 			// http.RoundTripper with a retry using the DefaultPolicy
 			// see: https://pkg.go.dev/oras.land/oras-go/v2/registry/remote/retry#Policy
-			Transport: retry.NewTransport(baseTransport),
+			Transport: &retry.Transport{
+				Base:   transport,
+				Policy: opts.retryPolicy,
+			},
 		},
 		Cache:  auth.NewCache(),
 		Header: opts.headers,
@@ -376,6 +567,9 @@ func (opts *Remote) NewRepository(reference string, common Common, logger logrus
 		return nil, err
 	}
 	repo.SkipReferrersGC = true
+	if opts.MaxMetadataBytes > 0 {
+		repo.MaxMetadataBytes = opts.MaxMetadataBytes
+	}
 	if opts.ReferrersAPI != nil {
 		if err := repo.SetReferrersCapability(*opts.ReferrersAPI); err != nil {
 			return nil, err
@@ -24,6 +24,7 @@ import (
 	_ "embed"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -32,9 +33,13 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/registry/remote/auth"
 )
 
@@ -613,3 +618,221 @@ func TestRemote_parseCustomHeaders(t *testing.T) {
 		})
 	}
 }
+
+func TestRemote_NewRepository_RetryPolicy(t *testing.T) {
+	count := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		http.Error(w, "error", http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	opts := struct {
+		Remote
+		Common
+	}{
+		Remote{
+			plainHTTP:    func() (bool, bool) { return true, true },
+			applyRetry:   true,
+			maxRetries:   1,
+			retryMinWait: time.Millisecond,
+			retryMaxWait: time.Millisecond,
+		},
+		Common{},
+	}
+	uri, err := url.ParseRequestURI(ts.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	repo, err := opts.NewRepository(uri.Host+"/"+testRepo, opts.Common, logrus.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err = repo.Tags(context.Background(), "", func([]string) error { return nil }); err == nil {
+		t.Fatal("expected error but got nil")
+	}
+	if want := 2; count != want {
+		t.Errorf("expected %d requests, got %d", want, count)
+	}
+}
+
+func TestRemote_Parse_retry(t *testing.T) {
+	cmd := &cobra.Command{}
+	opts := Remote{}
+	opts.EnableRetryFlags()
+	opts.ApplyFlags(cmd.Flags())
+	if err := cmd.Flags().Set("max-retries", "-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := opts.Parse(cmd); err == nil {
+		t.Error("expected error but got nil")
+	}
+}
+
+func TestRemote_Parse_retryWait(t *testing.T) {
+	for _, tt := range []struct {
+		flags   map[string]string
+		wantErr bool
+	}{
+		{flags: map[string]string{"retry-max-wait": "100ms"}, wantErr: false},
+		{flags: map[string]string{"retry-min-wait": "1s", "retry-max-wait": "2s"}, wantErr: false},
+		{flags: map[string]string{"retry-min-wait": "0s"}, wantErr: true},
+		{flags: map[string]string{"retry-min-wait": "2s", "retry-max-wait": "1s"}, wantErr: true},
+	} {
+		cmd := &cobra.Command{}
+		opts := Remote{}
+		opts.EnableRetryFlags()
+		opts.ApplyFlags(cmd.Flags())
+		for name, value := range tt.flags {
+			if err := cmd.Flags().Set(name, value); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := opts.Parse(cmd); (err != nil) != tt.wantErr {
+			t.Errorf("Parse() with %v error = %v, wantErr %v", tt.flags, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRemote_retryPolicy_firstWait(t *testing.T) {
+	opts := Remote{
+		applyRetry:   true,
+		maxRetries:   1,
+		retryMinWait: 50 * time.Millisecond,
+		retryMaxWait: time.Minute,
+	}
+	resp := &http.Response{StatusCode: http.StatusServiceUnavailable}
+	got, err := opts.retryPolicy().Retry(0, resp, nil)
+	if err != nil {
+		t.Fatal("Retry() error =", err)
+	}
+	// the initial wait is jittered by 10%
+	if got < 45*time.Millisecond || got > 55*time.Millisecond {
+		t.Errorf("Retry() first wait = %v, want about %v", got, opts.retryMinWait)
+	}
+}
+
+func TestRemote_retryPolicy_retryAfter(t *testing.T) {
+	opts := Remote{
+		applyRetry:   true,
+		maxRetries:   1,
+		retryMinWait: time.Millisecond,
+		retryMaxWait: 3 * time.Second,
+	}
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		wantMin    time.Duration
+		wantMax    time.Duration
+	}{
+		{name: "seconds beyond the maximum wait", status: http.StatusTooManyRequests, retryAfter: "30", wantMin: 30 * time.Second, wantMax: 30 * time.Second},
+		{name: "date beyond the maximum wait", status: http.StatusTooManyRequests, retryAfter: time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), wantMin: 50 * time.Second, wantMax: time.Minute},
+		{name: "invalid", status: http.StatusTooManyRequests, retryAfter: "soon", wantMin: 0, wantMax: 3 * time.Second},
+		{name: "not rate limited", status: http.StatusServiceUnavailable, retryAfter: "30", wantMin: 0, wantMax: 3 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{"Retry-After": []string{tt.retryAfter}}}
+			got, err := opts.retryPolicy().Retry(0, resp, nil)
+			if err != nil {
+				t.Fatal("Retry() error =", err)
+			}
+			if got < tt.wantMin || got > tt.wantMax {
+				t.Errorf("Retry() = %v, want between %v and %v", got, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"30"}}}
+	if got, _ := opts.retryPolicy().Retry(1, resp, nil); got >= 0 {
+		t.Errorf("Retry() after the last retry = %v, want a negative wait", got)
+	}
+}
+
+func TestRemote_ApplyFlags_retryAliases(t *testing.T) {
+	cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+	opts := Remote{}
+	opts.EnableRetryFlags()
+	opts.ApplyFlags(cmd.Flags())
+	cmd.SetArgs([]string{"--retries", "2", "--retry-initial-backoff", "1s", "--retry-max-backoff", "10s"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal("Execute() error =", err)
+	}
+	if opts.maxRetries != 2 || opts.retryMinWait != time.Second || opts.retryMaxWait != 10*time.Second {
+		t.Errorf("retry flags = %d, %v, %v, want 2, 1s, 10s", opts.maxRetries, opts.retryMinWait, opts.retryMaxWait)
+	}
+	if !cmd.Flags().Changed("retry-min-wait") {
+		t.Error("--retry-initial-backoff is not recorded as --retry-min-wait")
+	}
+}
+
+func TestRemote_Parse_blobTimeout(t *testing.T) {
+	cmd := &cobra.Command{}
+	opts := Remote{}
+	opts.EnableRetryFlags()
+	opts.ApplyFlags(cmd.Flags())
+	if err := cmd.Flags().Set("blob-timeout", "-1s"); err != nil {
+		t.Fatal(err)
+	}
+	if err := opts.Parse(cmd); err == nil {
+		t.Error("expected error but got nil")
+	}
+}
+
+func TestRemote_CheckMetadataSize(t *testing.T) {
+	desc := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Size: 100}
+	opts := Remote{}
+	if err := opts.CheckMetadataSize(desc); err != nil {
+		t.Errorf("CheckMetadataSize() without limit error = %v", err)
+	}
+	opts.MaxMetadataBytes = 100
+	if err := opts.CheckMetadataSize(desc); err != nil {
+		t.Errorf("CheckMetadataSize() within limit error = %v", err)
+	}
+	opts.MaxMetadataBytes = 99
+	if err := opts.CheckMetadataSize(desc); !errors.Is(err, errdef.ErrSizeExceedsLimit) {
+		t.Errorf("CheckMetadataSize() error = %v, want %v", err, errdef.ErrSizeExceedsLimit)
+	}
+}
+
+func TestRemote_NewRepository_MaxMetadataBytes(t *testing.T) {
+	opts := Remote{
+		plainHTTP:        func() (bool, bool) { return true, true },
+		MaxMetadataBytes: 1024,
+	}
+	repo, err := opts.NewRepository("localhost:5000/"+testRepo, Common{}, logrus.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.MaxMetadataBytes != 1024 {
+		t.Errorf("MaxMetadataBytes = %d, want 1024", repo.MaxMetadataBytes)
+	}
+}
+
+func TestRemote_ConnectionArgs(t *testing.T) {
+	cmd := &cobra.Command{}
+	opts := Remote{}
+	opts.ApplyFlags(cmd.Flags())
+	if got := opts.ConnectionArgs(); len(got) != 0 {
+		t.Errorf("ConnectionArgs() = %v, want none", got)
+	}
+	for _, arg := range [][2]string{
+		{"insecure", "true"},
+		{"plain-http", "false"},
+		{"ca-file", "ca.pem"},
+		{"cert-file", "cert.pem"},
+		{"key-file", "key.pem"},
+		{"resolve", "localhost:5000:127.0.0.1"},
+		{"registry-config", "config.json"},
+		{"username", "user"},
+		{"password", "secret"},
+		{"header", "Authorization: Bearer secret"},
+	} {
+		if err := cmd.Flags().Set(arg[0], arg[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"--insecure", "--plain-http=false", "--ca-file", "ca.pem", "--cert-file", "cert.pem", "--key-file", "key.pem", "--resolve", "localhost:5000:127.0.0.1", "--registry-config", "config.json"}
+	if got := opts.ConnectionArgs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ConnectionArgs() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras/cmd/oras/internal/display/status"
+)
+
+// writeTar writes the files under dir to w as a tar archive, named relative
+// to dir.
+func writeTar(w io.Writer, dir string) error {
+	tw := tar.NewWriter(w)
+	if err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		var link string
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if d.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		fp, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer fp.Close()
+		_, err = io.Copy(tw, fp)
+		return err
+	}); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// extractTar extracts the regular files of the tar archive r into dir and
+// returns their names in the archive. The other entries are skipped.
+func extractTar(r io.Reader, dir string) ([]string, error) {
+	var names []string
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return names, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if !filepath.IsLocal(name) {
+			return nil, fmt.Errorf("%s: %w", header.Name, file.ErrPathTraversalDisallowed)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return nil, err
+		}
+		if err := writeChunk(path, tr); err != nil {
+			return nil, err
+		}
+		names = append(names, filepath.ToSlash(name))
+	}
+}
+
+// loadTar loads the files of the tar archive at path, or stdin if path is
+// "-", into store. The files are extracted into tempDir.
+func loadTar(ctx context.Context, store *file.Store, sources fileSources, annotations map[string]map[string]string, path string, stdin io.Reader, tempDir string, displayStatus status.PushHandler) ([]ocispec.Descriptor, error) {
+	r := stdin
+	if path != "-" {
+		fp, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer fp.Close()
+		r = fp
+	}
+	return loadTarFiles(ctx, store, sources, annotations, r, tempDir, displayStatus)
+}
+
+// loadTarFiles adds the files of the tar archive r to store, named as in the
+// archive.
+func loadTarFiles(ctx context.Context, store *file.Store, sources fileSources, annotations map[string]map[string]string, r io.Reader, tempDir string, displayStatus status.PushHandler) ([]ocispec.Descriptor, error) {
+	names, err := extractTar(r, tempDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the tar archive: %w", err)
+	}
+	var files []ocispec.Descriptor
+	for _, name := range names {
+		if err := displayStatus.OnFileLoading(name); err != nil {
+			return nil, err
+		}
+		desc, err := addFile(ctx, store, sources, name, "", filepath.Join(tempDir, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range annotations[name] {
+			desc.Annotations[k] = v
+		}
+		files = append(files, desc)
+	}
+	if len(files) == 0 {
+		if err := displayStatus.OnEmptyArtifact(); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
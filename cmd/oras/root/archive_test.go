@@ -0,0 +1,119 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"oras.land/oras-go/v2/content/file"
+)
+
+func Test_writeTar(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "hi.txt"), []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("sub/hi.txt", filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeTar(&buf, dir); err != nil {
+		t.Fatal("writeTar() error =", err)
+	}
+	var names []string
+	tr := tar.NewReader(&buf)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, header.Name)
+		switch header.Name {
+		case "link":
+			if header.Typeflag != tar.TypeSymlink || header.Linkname != "sub/hi.txt" {
+				t.Errorf("writeTar() link = %q -> %q, want symlink -> %q", header.Name, header.Linkname, "sub/hi.txt")
+			}
+		case "sub/hi.txt":
+			got, err := io.ReadAll(tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != "hello" {
+				t.Errorf("writeTar() content = %q, want %q", got, "hello")
+			}
+		}
+	}
+	want := []string{"link", "sub/", "sub/hi.txt"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("writeTar() names = %v, want %v", names, want)
+	}
+}
+
+func Test_extractTar(t *testing.T) {
+	archive := func(names ...string) *bytes.Buffer {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for _, name := range names {
+			if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(name))}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tw.Write([]byte(name)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "hi.txt"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return &buf
+	}
+
+	dir := t.TempDir()
+	names, err := extractTar(archive("hi.txt", "./sub/b.txt"), dir)
+	if err != nil {
+		t.Fatal("extractTar() error =", err)
+	}
+	if want := []string{"hi.txt", "sub/b.txt"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("extractTar() = %v, want %v", names, want)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "sub", "b.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "./sub/b.txt" {
+		t.Errorf("extracted content = %q, want %q", got, "./sub/b.txt")
+	}
+
+	if _, err := extractTar(archive("../evil"), t.TempDir()); !errors.Is(err, file.ErrPathTraversalDisallowed) {
+		t.Errorf("extractTar() error = %v, want %v", err, file.ErrPathTraversalDisallowed)
+	}
+}
@@ -17,6 +17,7 @@ package root
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -42,8 +43,9 @@ type attachOptions struct {
 	option.Format
 	option.Platform
 
-	artifactType string
-	concurrency  int
+	artifactType         string
+	concurrency          int
+	inheritedAnnotations []string
 }
 
 func attachCmd() *cobra.Command {
@@ -77,6 +79,9 @@ Example - Attach an artifact with manifest annotations:
 Example - Attach file 'hi.txt' and add manifest annotations:
   oras attach --artifact-type doc/example --annotation "key=val" localhost:5000/hello:v1 hi.txt
 
+Example - Attach file 'hi.txt' and copy the annotation 'org.opencontainers.image.source' from the subject manifest:
+  oras attach --artifact-type doc/example --inherit-annotation org.opencontainers.image.source localhost:5000/hello:v1 hi.txt
+
 Example - Attach file 'hi.txt' and export the pushed manifest to 'manifest.json':
   oras attach --artifact-type doc/example --export-manifest manifest.json localhost:5000/hello:v1 hi.txt
 
@@ -109,6 +114,7 @@ Example - Attach file to the manifest tagged 'v1' in an OCI image layout folder
 
 	cmd.Flags().StringVarP(&opts.artifactType, "artifact-type", "", "", "artifact type")
 	cmd.Flags().IntVarP(&opts.concurrency, "concurrency", "", 5, "concurrency level")
+	cmd.Flags().StringArrayVarP(&opts.inheritedAnnotations, "inherit-annotation", "", nil, "[Preview] copy the manifest annotation of `key` from the subject, if present")
 	opts.FlagDescription = "[Preview] attach to an arch-specific subject"
 	_ = cmd.MarkFlagRequired("artifact-type")
 	opts.EnableDistributionSpecFlag()
@@ -156,10 +162,19 @@ func runAttach(cmd *cobra.Command, opts *attachOptions) error {
 	if err != nil {
 		return fmt.Errorf("failed to resolve %s: %w", opts.Reference, err)
 	}
-	descs, err := loadFiles(ctx, store, annotations, opts.FileRefs, displayStatus)
+	if len(opts.inheritedAnnotations) != 0 {
+		annotations, err = inheritAnnotations(ctx, dst, subject, annotations, opts.inheritedAnnotations)
+		if err != nil {
+			return err
+		}
+	}
+	descs, err := loadFiles(ctx, store, nil, annotations, opts.FileRefs, onDuplicateError, displayStatus)
 	if err != nil {
 		return err
 	}
+	if err := applyPatternAnnotations(annotations, descs); err != nil {
+		return err
+	}
 
 	// prepare push
 	dst, stopTrack, err := displayStatus.TrackTarget(dst)
@@ -210,3 +225,35 @@ func runAttach(cmd *cobra.Command, opts *attachOptions) error {
 	// Export manifest
 	return opts.ExportManifest(ctx, store, root)
 }
+
+// inheritAnnotations copies the manifest annotations of keys from the subject
+// to the manifest annotations to be attached. Keys missing on the subject or
+// explicitly specified by the user are skipped.
+func inheritAnnotations(ctx context.Context, fetcher content.Fetcher, subject ocispec.Descriptor, annotations map[string]map[string]string, keys []string) (map[string]map[string]string, error) {
+	subjectBytes, err := content.FetchAll(ctx, fetcher, subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch subject %s: %w", subject.Digest, err)
+	}
+	var manifest struct {
+		Annotations map[string]string `json:"annotations"`
+	}
+	if err := json.Unmarshal(subjectBytes, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse subject %s: %w", subject.Digest, err)
+	}
+	for _, key := range keys {
+		value, ok := manifest.Annotations[key]
+		if !ok {
+			continue
+		}
+		if annotations == nil {
+			annotations = make(map[string]map[string]string)
+		}
+		if annotations[option.AnnotationManifest] == nil {
+			annotations[option.AnnotationManifest] = make(map[string]string)
+		}
+		if _, ok := annotations[option.AnnotationManifest][key]; !ok {
+			annotations[option.AnnotationManifest][key] = value
+		}
+	}
+	return annotations, nil
+}
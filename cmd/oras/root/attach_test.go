@@ -16,10 +16,15 @@ limitations under the License.
 package root
 
 import (
+	"bytes"
 	"context"
+	"reflect"
 	"testing"
 
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
 	"oras.land/oras/cmd/oras/internal/errors"
 	"oras.land/oras/cmd/oras/internal/option"
 )
@@ -41,3 +46,27 @@ func Test_runAttach_errType(t *testing.T) {
 		t.Fatalf("got %v, want %v", got, want)
 	}
 }
+
+func Test_inheritAnnotations(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	subjectBytes := []byte(`{"annotations":{"source":"subject","repository":"subject","other":"subject"}}`)
+	subject := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, subjectBytes)
+	if err := store.Push(ctx, subject, bytes.NewReader(subjectBytes)); err != nil {
+		t.Fatal(err)
+	}
+	annotations := map[string]map[string]string{
+		option.AnnotationManifest: {"repository": "user"},
+	}
+
+	got, err := inheritAnnotations(ctx, store, subject, annotations, []string{"source", "repository", "missing"})
+	if err != nil {
+		t.Fatal("inheritAnnotations() error =", err)
+	}
+	want := map[string]map[string]string{
+		option.AnnotationManifest: {"source": "subject", "repository": "user"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("inheritAnnotations() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content/file"
+)
+
+const (
+	// annotationFileMode is the permission bits of a pushed file in octal.
+	annotationFileMode = "land.oras.file.mode"
+	// annotationFileUID is the user ID of the owner of a pushed file.
+	annotationFileUID = "land.oras.file.uid"
+	// annotationFileGID is the group ID of the owner of a pushed file.
+	annotationFileGID = "land.oras.file.gid"
+	// annotationFileModTime is the modification time of a pushed file.
+	annotationFileModTime = "land.oras.file.mtime"
+)
+
+// setFileAttributes records the mode, the ownership and the modification time
+// of the source files of layers as annotations. Directories are skipped since their
+// tarballs already keep the attributes of their files.
+func setFileAttributes(sources fileSources, layers []ocispec.Descriptor) error {
+	for _, layer := range layers {
+		name := layer.Annotations[ocispec.AnnotationTitle]
+		if _, ok := layer.Annotations[annotationSymlink]; ok || name == "" || layer.Annotations[file.AnnotationUnpack] == "true" {
+			continue
+		}
+		path, err := sources.path(name)
+		if err != nil {
+			return err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		layer.Annotations[annotationFileMode] = fmt.Sprintf("%04o", info.Mode().Perm())
+		layer.Annotations[annotationFileModTime] = info.ModTime().UTC().Format(time.RFC3339Nano)
+		if uid, gid, ok := fileOwner(info); ok {
+			layer.Annotations[annotationFileUID] = strconv.Itoa(uid)
+			layer.Annotations[annotationFileGID] = strconv.Itoa(gid)
+		}
+	}
+	return nil
+}
+
+// restoreFileAttributes restores the attributes recorded in annotations by
+// setFileAttributes to the file at path. The ownership is only restored when
+// running as root.
+func restoreFileAttributes(path string, annotations map[string]string) error {
+	if value, ok := annotations[annotationFileMode]; ok {
+		mode, err := strconv.ParseUint(value, 8, 32)
+		if err != nil || mode > uint64(os.ModePerm) {
+			return fmt.Errorf("invalid file mode %q of %s", value, path)
+		}
+		if err := os.Chmod(path, os.FileMode(mode)); err != nil {
+			return err
+		}
+	}
+	if err := restoreFileOwner(path, annotations); err != nil {
+		return err
+	}
+	if value, ok := annotations[annotationFileModTime]; ok {
+		modTime, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return fmt.Errorf("invalid modification time %q of %s: %w", value, path, err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func Test_setFileAttributes(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "run.sh")
+	if err := os.WriteFile(src, []byte("#!/bin/sh"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(src, 0750); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	if err := os.Chtimes(src, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	layer := ocispec.Descriptor{Annotations: map[string]string{ocispec.AnnotationTitle: "run.sh"}}
+	if err := setFileAttributes(fileSources{"run.sh": src}, []ocispec.Descriptor{layer}); err != nil {
+		t.Fatal("setFileAttributes() error =", err)
+	}
+	if got := layer.Annotations[annotationFileMode]; got != "0750" {
+		t.Errorf("mode annotation = %q, want %q", got, "0750")
+	}
+	if got, want := layer.Annotations[annotationFileModTime], "2024-01-02T03:04:05.000000006Z"; got != want {
+		t.Errorf("modification time annotation = %q, want %q", got, want)
+	}
+
+	// restore to a pulled file
+	dst := filepath.Join(dir, "pulled.sh")
+	if err := os.WriteFile(dst, []byte("#!/bin/sh"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := restoreFileAttributes(dst, layer.Annotations); err != nil {
+		t.Fatal("restoreFileAttributes() error =", err)
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0750 {
+		t.Errorf("restored mode = %o, want %o", info.Mode().Perm(), 0750)
+	}
+	if !info.ModTime().Equal(modTime) {
+		t.Errorf("restored modification time = %v, want %v", info.ModTime(), modTime)
+	}
+
+	if err := restoreFileAttributes(dst, map[string]string{annotationFileMode: "9"}); err == nil {
+		t.Error("restoreFileAttributes() with an invalid mode error = nil, want error")
+	}
+}
@@ -0,0 +1,52 @@
+//go:build !windows
+
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// fileOwner returns the user ID and the group ID of the owner of a file.
+func fileOwner(info os.FileInfo) (uid, gid int, ok bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(stat.Uid), int(stat.Gid), true
+}
+
+// restoreFileOwner changes the owner of the file at path to the IDs recorded
+// in annotations if running as root.
+func restoreFileOwner(path string, annotations map[string]string) error {
+	uidValue, ok := annotations[annotationFileUID]
+	if !ok || os.Geteuid() != 0 {
+		return nil
+	}
+	uid, err := strconv.Atoi(uidValue)
+	if err != nil {
+		return fmt.Errorf("invalid user ID %q of %s", uidValue, path)
+	}
+	gid, err := strconv.Atoi(annotations[annotationFileGID])
+	if err != nil {
+		return fmt.Errorf("invalid group ID %q of %s", annotations[annotationFileGID], path)
+	}
+	return os.Lchown(path, uid, gid)
+}
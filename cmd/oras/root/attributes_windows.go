@@ -0,0 +1,32 @@
+//go:build windows
+
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import "os"
+
+// fileOwner returns false since the ownership of files is not recorded on
+// Windows.
+func fileOwner(_ os.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
+
+// restoreFileOwner does nothing since the ownership of files is not restored
+// on Windows.
+func restoreFileOwner(_ string, _ map[string]string) error {
+	return nil
+}
@@ -0,0 +1,210 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content/file"
+	oerrors "oras.land/oras/cmd/oras/internal/errors"
+)
+
+const (
+	// compressionGzip is the gzip compression of layers.
+	compressionGzip = "gzip"
+	// compressionZstd is the zstd compression of layers.
+	compressionZstd = "zstd"
+	// annotationUncompressedDigest is the digest of the content of a layer
+	// compressed at push time.
+	annotationUncompressedDigest = "land.oras.uncompressed.digest"
+)
+
+// compressLayers compresses the file layers, read from their sources, with
+// gzip into tempDir and adds them to store. The compressed layers are named with the ".gz" extension
+// and their media types are suffixed with "+gzip". Directories, which are
+// already compressed, are left as is.
+func compressLayers(ctx context.Context, store *file.Store, sources fileSources, layers []ocispec.Descriptor, tempDir string) ([]ocispec.Descriptor, error) {
+	ret := make([]ocispec.Descriptor, 0, len(layers))
+	for i, layer := range layers {
+		name := layer.Annotations[ocispec.AnnotationTitle]
+		if _, ok := layer.Annotations[annotationSymlink]; ok || name == "" || layer.Annotations[file.AnnotationUnpack] == "true" {
+			ret = append(ret, layer)
+			continue
+		}
+		src, err := sources.path(name)
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(tempDir, strconv.Itoa(i)+".gz")
+		if err := gzipFile(path, src); err != nil {
+			return nil, err
+		}
+		mediaType := layer.MediaType
+		if !strings.HasSuffix(mediaType, "+"+compressionGzip) {
+			mediaType += "+" + compressionGzip
+		}
+		compressed, err := addFile(ctx, store, sources, name+".gz", mediaType, path)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range layer.Annotations {
+			if k != ocispec.AnnotationTitle {
+				compressed.Annotations[k] = v
+			}
+		}
+		compressed.Annotations[annotationUncompressedDigest] = layer.Digest.String()
+		ret = append(ret, compressed)
+	}
+	return ret, nil
+}
+
+// gzipFile compresses the file at src into dst.
+func gzipFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	// the gzip header is left empty so that the output is reproducible
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// decompressedName returns the name of the decompressed file of a gzip
+// layer, which is the layer name without the ".gz" extension. The name of a
+// chunk is the one of the file it belongs to.
+func decompressedName(layer ocispec.Descriptor) (string, bool) {
+	if !strings.HasSuffix(layer.MediaType, "+"+compressionGzip) {
+		return "", false
+	}
+	name := layer.Annotations[ocispec.AnnotationTitle]
+	if chunkOf, ok := layer.Annotations[annotationChunkOf]; ok {
+		name = chunkOf
+	}
+	return strings.CutSuffix(name, ".gz")
+}
+
+// decompressLayers decompresses the pulled gzip layers in outputDir into the
+// files named without the ".gz" extension, verified against the
+// uncompressed digest annotation if present. The compressed files are
+// removed.
+func decompressLayers(outputDir string, layers []ocispec.Descriptor, keepOldFiles bool) error {
+	for _, layer := range layers {
+		name := layer.Annotations[ocispec.AnnotationTitle]
+		if name == "" || layer.Annotations[file.AnnotationUnpack] == "true" {
+			continue
+		}
+		if strings.HasSuffix(layer.MediaType, "+"+compressionZstd) {
+			return &oerrors.Error{
+				Err:            fmt.Errorf("failed to decompress %s: %s compression is not supported", name, compressionZstd),
+				Recommendation: "Pull the file without --decompress and decompress it with the zstd tool",
+			}
+		}
+		newName, ok := decompressedName(layer)
+		if !ok {
+			continue
+		}
+		var expected digest.Digest
+		if value, ok := layer.Annotations[annotationUncompressedDigest]; ok {
+			var err error
+			if expected, err = digest.Parse(value); err != nil {
+				return fmt.Errorf("invalid uncompressed digest of %s: %w", name, err)
+			}
+		}
+		resolve := func(name string) string {
+			if filepath.IsAbs(name) {
+				return name
+			}
+			return filepath.Join(outputDir, name)
+		}
+		path := resolve(name)
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			// already decompressed
+			continue
+		}
+		if err := gunzipFile(resolve(newName), path, expected, keepOldFiles); err != nil {
+			return fmt.Errorf("failed to decompress %s: %w", name, err)
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gunzipFile decompresses the file at src into dst. The decompressed content
+// is verified if expected is not empty.
+func gunzipFile(dst, src string, expected digest.Digest, keepOldFiles bool) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	zr, err := gzip.NewReader(in)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if keepOldFiles {
+		flag |= os.O_EXCL
+	}
+	out, err := os.OpenFile(dst, flag, 0666)
+	if err != nil {
+		return err
+	}
+	var w io.Writer = out
+	var verifier digest.Verifier
+	if expected != "" {
+		verifier = expected.Verifier()
+		w = io.MultiWriter(out, verifier)
+	}
+	_, err = io.Copy(w, zr)
+	if err == nil && verifier != nil && !verifier.Verified() {
+		err = errors.New("mismatched uncompressed digest")
+	}
+	if err != nil {
+		// do not leave a partial or unverified file behind
+		out.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,231 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/file"
+)
+
+func Test_compressLayers(t *testing.T) {
+	ctx := context.Background()
+	srcDir := t.TempDir()
+	data := []byte("hello world")
+	if err := os.WriteFile(filepath.Join(srcDir, "hi.txt"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(srcDir, "dir"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(srcDir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+
+	store, err := file.New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	sources := make(fileSources)
+	fileDesc, err := addFile(ctx, store, sources, "hi.txt", "", "hi.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileDesc.Annotations["foo"] = "bar"
+	dirDesc, err := addFile(ctx, store, sources, "dir", "", "dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := compressLayers(ctx, store, sources, []ocispec.Descriptor{fileDesc, dirDesc}, t.TempDir())
+	if err != nil {
+		t.Fatal("compressLayers() error =", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("compressLayers() returned %d layers, want 2", len(got))
+	}
+	if got[1].Digest != dirDesc.Digest {
+		t.Errorf("directory layer = %v, want %v", got[1], dirDesc)
+	}
+	compressed := got[0]
+	if want := ocispec.MediaTypeImageLayerGzip; compressed.MediaType != want {
+		t.Errorf("media type = %s, want %s", compressed.MediaType, want)
+	}
+	if want := "hi.txt.gz"; compressed.Annotations[ocispec.AnnotationTitle] != want {
+		t.Errorf("name = %s, want %s", compressed.Annotations[ocispec.AnnotationTitle], want)
+	}
+	if want := fileDesc.Digest.String(); compressed.Annotations[annotationUncompressedDigest] != want {
+		t.Errorf("uncompressed digest = %s, want %s", compressed.Annotations[annotationUncompressedDigest], want)
+	}
+	if compressed.Annotations["foo"] != "bar" {
+		t.Errorf("file annotation is not kept, got %v", compressed.Annotations)
+	}
+
+	rc, err := store.Fetch(ctx, compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	zr, err := gzip.NewReader(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != string(data) {
+		t.Errorf("decompressed content = %q, want %q", content, data)
+	}
+}
+
+func Test_decompressLayers(t *testing.T) {
+	data := []byte("hello world")
+	tests := []struct {
+		name         string
+		uncompressed string
+		wantErr      bool
+	}{
+		{
+			name:         "verified",
+			uncompressed: digest.FromBytes(data).String(),
+		},
+		{
+			name: "not verified",
+		},
+		{
+			name:         "mismatched digest",
+			uncompressed: digest.FromString("foo").String(),
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := filepath.Join(t.TempDir(), "hi.txt")
+			if err := os.WriteFile(src, data, 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := gzipFile(filepath.Join(dir, "hi.txt.gz"), src); err != nil {
+				t.Fatal(err)
+			}
+			layer := ocispec.Descriptor{
+				MediaType: ocispec.MediaTypeImageLayerGzip,
+				Annotations: map[string]string{
+					ocispec.AnnotationTitle: "hi.txt.gz",
+				},
+			}
+			if tt.uncompressed != "" {
+				layer.Annotations[annotationUncompressedDigest] = tt.uncompressed
+			}
+			err := decompressLayers(dir, []ocispec.Descriptor{layer}, false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decompressLayers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if _, err := os.Stat(filepath.Join(dir, "hi.txt")); !errors.Is(err, fs.ErrNotExist) {
+					t.Errorf("unverified file is not removed, stat error = %v", err)
+				}
+				return
+			}
+			got, err := os.ReadFile(filepath.Join(dir, "hi.txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(data) {
+				t.Errorf("decompressed content = %q, want %q", got, data)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "hi.txt.gz")); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("compressed file is not removed, stat error = %v", err)
+			}
+		})
+	}
+}
+
+func Test_decompressLayers_joinedChunks(t *testing.T) {
+	ctx := context.Background()
+	srcDir := t.TempDir()
+	data := bytes.Repeat([]byte("hello world"), 100)
+	if err := os.WriteFile(filepath.Join(srcDir, "hi.txt"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	store, err := file.New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	sources := make(fileSources)
+	layer, err := addFile(ctx, store, sources, "hi.txt", "", filepath.Join(srcDir, "hi.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tempDir := t.TempDir()
+	layers, err := compressLayers(ctx, store, sources, []ocispec.Descriptor{layer}, tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layers, err = splitLayers(ctx, store, sources, layers, 16, tempDir); err != nil {
+		t.Fatal(err)
+	}
+
+	// simulate pulling the chunks
+	outputDir := t.TempDir()
+	for _, layer := range layers {
+		chunk, err := content.FetchAll(ctx, store, layer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(outputDir, layer.Annotations[ocispec.AnnotationTitle]), chunk, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	joined, err := joinChunks(outputDir, layers, false)
+	if err != nil {
+		t.Fatal("joinChunks() error =", err)
+	}
+	if err := decompressLayers(outputDir, joined, false); err != nil {
+		t.Fatal("decompressLayers() error =", err)
+	}
+	got, err := os.ReadFile(filepath.Join(outputDir, "hi.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("decompressed content = %q, want %q", got, data)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "hi.txt.gz")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("compressed file is not removed, stat error = %v", err)
+	}
+}
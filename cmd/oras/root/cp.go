@@ -38,6 +38,7 @@ import (
 	oerrors "oras.land/oras/cmd/oras/internal/errors"
 	"oras.land/oras/cmd/oras/internal/option"
 	"oras.land/oras/cmd/oras/internal/output"
+	"oras.land/oras/internal/contentutil"
 	"oras.land/oras/internal/docker"
 	"oras.land/oras/internal/graph"
 	"oras.land/oras/internal/listener"
@@ -120,6 +121,15 @@ func runCopy(cmd *cobra.Command, opts *copyOptions) error {
 	if err := opts.EnsureSourceTargetReferenceNotEmpty(cmd); err != nil {
 		return err
 	}
+	var source oras.ReadOnlyGraphTarget = src
+	if opts.From.Type == option.TargetTypeOCILayout {
+		// content staged in an OCI image layout is verified before and during
+		// copying, so that an incomplete or corrupted layout fails fast
+		source, err = verifyStagedSource(ctx, src, opts)
+		if err != nil {
+			return err
+		}
+	}
 
 	// Prepare destination
 	dst, err := opts.To.NewTarget(opts.Common, logger)
@@ -128,7 +138,7 @@ func runCopy(cmd *cobra.Command, opts *copyOptions) error {
 	}
 	ctx = registryutil.WithScopeHint(ctx, dst, auth.ActionPull, auth.ActionPush)
 
-	desc, err := doCopy(ctx, opts.Printer, src, dst, opts)
+	desc, err := doCopy(ctx, opts.Printer, source, dst, opts)
 	if err != nil {
 		return err
 	}
@@ -154,11 +164,29 @@ func runCopy(cmd *cobra.Command, opts *copyOptions) error {
 	return nil
 }
 
+// verifyStagedSource ensures that the graph of the source artifact is complete
+// in src and returns a target verifying the fetched content.
+func verifyStagedSource(ctx context.Context, src oras.ReadOnlyGraphTarget, opts *copyOptions) (oras.ReadOnlyGraphTarget, error) {
+	rOpts := oras.DefaultResolveOptions
+	rOpts.TargetPlatform = opts.Platform.Platform
+	root, err := oras.Resolve(ctx, src, opts.From.Reference, rOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", opts.From.Reference, err)
+	}
+	if err := contentutil.EnsureGraphExists(ctx, src, root); err != nil {
+		return nil, fmt.Errorf("%s is incomplete: %w", opts.From.RawReference, err)
+	}
+	return contentutil.VerifiedReadOnlyGraphTarget(src), nil
+}
+
 func doCopy(ctx context.Context, printer *output.Printer, src oras.ReadOnlyGraphTarget, dst oras.GraphTarget, opts *copyOptions) (ocispec.Descriptor, error) {
 	// Prepare copy options
 	committed := &sync.Map{}
 	extendedCopyOptions := oras.DefaultExtendedCopyOptions
 	extendedCopyOptions.Concurrency = opts.concurrency
+	if opts.From.MaxMetadataBytes > 0 {
+		extendedCopyOptions.MaxMetadataBytes = opts.From.MaxMetadataBytes
+	}
 	extendedCopyOptions.FindPredecessors = func(ctx context.Context, src content.ReadOnlyGraphStorage, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
 		return registry.Referrers(ctx, src, desc, "")
 	}
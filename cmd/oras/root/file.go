@@ -17,48 +17,53 @@ package root
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"io/fs"
+	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2/content/file"
 	"oras.land/oras/cmd/oras/internal/display/status"
+	oerrors "oras.land/oras/cmd/oras/internal/errors"
 	"oras.land/oras/cmd/oras/internal/fileref"
+	"oras.land/oras/cmd/oras/internal/option"
+	"oras.land/oras/internal/git"
 )
 
-func loadFiles(ctx context.Context, store *file.Store, annotations map[string]map[string]string, fileRefs []string, displayStatus status.PushHandler) ([]ocispec.Descriptor, error) {
-	var files []ocispec.Descriptor
-	for _, fileRef := range fileRefs {
-		filename, mediaType, err := fileref.Parse(fileRef, "")
-		if err != nil {
-			return nil, err
-		}
+// errAllLayersFiltered is returned when every loaded layer is dropped by a
+// layer filter.
+var errAllLayersFiltered = errors.New("all layers are excluded by the layer filter")
 
-		// get shortest absolute path as unique name
-		name := filepath.Clean(filename)
-		if !filepath.IsAbs(name) {
-			name = filepath.ToSlash(name)
-		}
+// Policies of loading a file whose name is already loaded.
+const (
+	onDuplicateError  = "error"
+	onDuplicateSkip   = "skip"
+	onDuplicateRename = "rename"
+)
 
-		err = displayStatus.OnFileLoading(name)
-		if err != nil {
-			return nil, err
-		}
-		file, err := addFile(ctx, store, name, mediaType, filename)
+// onDuplicatePolicies lists the policies of loading a file whose name is
+// already loaded.
+var onDuplicatePolicies = []string{onDuplicateError, onDuplicateSkip, onDuplicateRename}
+
+func loadFiles(ctx context.Context, store *file.Store, sources fileSources, annotations map[string]map[string]string, fileRefs []string, onDuplicate string, displayStatus status.PushHandler) ([]ocispec.Descriptor, error) {
+	var files []ocispec.Descriptor
+	for _, fileRef := range fileRefs {
+		desc, ok, err := loadFile(ctx, store, sources, annotations, fileRef, onDuplicate, displayStatus)
 		if err != nil {
 			return nil, err
 		}
-		if value, ok := annotations[filename]; ok {
-			if file.Annotations == nil {
-				file.Annotations = value
-			} else {
-				for k, v := range value {
-					file.Annotations[k] = v
-				}
-			}
+		if ok {
+			files = append(files, desc)
 		}
-		files = append(files, file)
 	}
 	if len(files) == 0 {
 		if err := displayStatus.OnEmptyArtifact(); err != nil {
@@ -68,7 +73,104 @@ func loadFiles(ctx context.Context, store *file.Store, annotations map[string]ma
 	return files, nil
 }
 
-func addFile(ctx context.Context, store *file.Store, name string, mediaType string, filename string) (ocispec.Descriptor, error) {
+// loadFile adds the file of fileRef to store. ok is false if the file is
+// skipped since its name is already loaded.
+func loadFile(ctx context.Context, store *file.Store, sources fileSources, annotations map[string]map[string]string, fileRef string, onDuplicate string, displayStatus status.PushHandler) (desc ocispec.Descriptor, ok bool, err error) {
+	filename, mediaType, err := fileref.Parse(fileRef, "")
+	if err != nil {
+		return ocispec.Descriptor{}, false, err
+	}
+
+	// get shortest absolute path as unique name
+	name := filepath.Clean(filename)
+	if !filepath.IsAbs(name) {
+		name = filepath.ToSlash(name)
+	}
+
+	err = displayStatus.OnFileLoading(name)
+	if err != nil {
+		return ocispec.Descriptor{}, false, err
+	}
+	desc, err = addFile(ctx, store, sources, name, mediaType, filename)
+	if errors.Is(err, file.ErrDuplicateName) {
+		switch onDuplicate {
+		case onDuplicateSkip:
+			return ocispec.Descriptor{}, false, nil
+		case onDuplicateRename:
+			for i := 1; errors.Is(err, file.ErrDuplicateName); i++ {
+				desc, err = addFile(ctx, store, sources, renameDuplicate(name, i), mediaType, filename)
+			}
+		}
+	}
+	if err != nil {
+		return ocispec.Descriptor{}, false, err
+	}
+	if value, ok := annotations[filename]; ok {
+		if desc.Annotations == nil {
+			desc.Annotations = value
+		} else {
+			for k, v := range value {
+				desc.Annotations[k] = v
+			}
+		}
+	}
+	return desc, true, nil
+}
+
+// renameDuplicate returns the i-th name for a file whose name is already
+// loaded, with "-<i>" appended to the name before its extension.
+func renameDuplicate(name string, i int) string {
+	ext := path.Ext(path.Base(name))
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), i, ext)
+}
+
+// isStdinRef returns true if the file reference is "-", optionally with a
+// media type, which refers to stdin.
+func isStdinRef(fileRef string) bool {
+	path, _, err := fileref.Parse(fileRef, "")
+	return err == nil && path == "-"
+}
+
+// loadStdin adds the content read from stdin to store as the file name,
+// with the media type of fileRef. The content is written to path.
+func loadStdin(ctx context.Context, store *file.Store, sources fileSources, annotations map[string]map[string]string, fileRef string, name string, stdin io.Reader, path string, displayStatus status.PushHandler) (ocispec.Descriptor, error) {
+	_, mediaType, err := fileref.Parse(fileRef, "")
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := displayStatus.OnFileLoading(name); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := writeChunk(path, stdin); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	desc, err := addFile(ctx, store, sources, name, mediaType, path)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	for k, v := range annotations[name] {
+		desc.Annotations[k] = v
+	}
+	return desc, nil
+}
+
+// fileSources maps the names of the files added to a file store to the paths
+// they are read from, which differ from the names when the files are renamed,
+// copied into a temporary directory or read from stdin.
+type fileSources map[string]string
+
+// path returns the path the file name is read from.
+func (s fileSources) path(name string) (string, error) {
+	path, ok := s[name]
+	if !ok {
+		return "", fmt.Errorf("unknown source of the file %s", name)
+	}
+	return path, nil
+}
+
+// addFile adds the file at filename to store as name, recording filename in
+// sources if not nil.
+func addFile(ctx context.Context, store *file.Store, sources fileSources, name string, mediaType string, filename string) (ocispec.Descriptor, error) {
 	file, err := store.Add(ctx, name, mediaType, filename)
 	if err != nil {
 		var pathErr *fs.PathError
@@ -77,5 +179,236 @@ func addFile(ctx context.Context, store *file.Store, name string, mediaType stri
 		}
 		return ocispec.Descriptor{}, err
 	}
+	if sources != nil {
+		sources[name] = filename
+	}
 	return file, nil
 }
+
+// filterLayers removes the layers for which keep returns false. Only layers
+// are passed to keep, the config and the subject descriptors are never
+// filtered.
+func filterLayers(layers []ocispec.Descriptor, keep func(desc ocispec.Descriptor) bool) ([]ocispec.Descriptor, error) {
+	if keep == nil || len(layers) == 0 {
+		return layers, nil
+	}
+	var kept []ocispec.Descriptor
+	for _, layer := range layers {
+		if keep(layer) {
+			kept = append(kept, layer)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, &oerrors.Error{
+			Err:            errAllLayersFiltered,
+			Recommendation: "Please make sure at least one file is not excluded, or push an empty artifact without file arguments",
+		}
+	}
+	return kept, nil
+}
+
+// isAnnotationPattern returns true if the key of the annotation file is a
+// pattern matching file names rather than a file name.
+func isAnnotationPattern(key string) bool {
+	return key != option.AnnotationManifest && key != option.AnnotationConfig && strings.ContainsAny(key, "*?[")
+}
+
+// applyPatternAnnotations adds the annotations of the pattern keys in
+// annotations to every layer whose name matches, in the syntax of ignore
+// files. Annotations set for the exact file name take precedence, followed by
+// the patterns in lexical order.
+func applyPatternAnnotations(annotations map[string]map[string]string, layers []ocispec.Descriptor) error {
+	var keys []string
+	for key := range annotations {
+		if isAnnotationPattern(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		pattern, err := compileIgnorePattern(key)
+		if err != nil {
+			return fmt.Errorf("invalid annotation pattern %q: %w", key, err)
+		}
+		if pattern.negate {
+			return fmt.Errorf("invalid annotation pattern %q: negation is not supported", key)
+		}
+		for _, layer := range layers {
+			name := layer.Annotations[ocispec.AnnotationTitle]
+			if name == "" || !pattern.re.MatchString(name) {
+				continue
+			}
+			for k, v := range annotations[key] {
+				if _, ok := layer.Annotations[k]; !ok {
+					layer.Annotations[k] = v
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// setManifestAnnotation adds the manifest annotation key with value to the
+// loaded annotations. It fails if the key is already set by the user.
+func setManifestAnnotation(annotations map[string]map[string]string, key, value string) (map[string]map[string]string, error) {
+	if annotations == nil {
+		annotations = make(map[string]map[string]string)
+	}
+	manifestAnnotations := annotations[option.AnnotationManifest]
+	if manifestAnnotations == nil {
+		manifestAnnotations = make(map[string]string)
+		annotations[option.AnnotationManifest] = manifestAnnotations
+	}
+	if _, ok := manifestAnnotations[key]; ok {
+		return nil, fmt.Errorf("manifest annotation %q is generated and cannot be specified at the same time", key)
+	}
+	manifestAnnotations[key] = value
+	return annotations, nil
+}
+
+// loadSidecarAnnotations loads the annotations of each file in fileRefs from
+// its sidecar file, which is the file path appended with suffix, and merges
+// them into annotations. Stdin and files without a sidecar file are skipped.
+// Annotations already present in annotations take precedence over the sidecar
+// ones.
+func loadSidecarAnnotations(annotations map[string]map[string]string, fileRefs []string, suffix string) (map[string]map[string]string, error) {
+	for _, fileRef := range fileRefs {
+		if isStdinRef(fileRef) {
+			continue
+		}
+		filename, _, err := fileref.Parse(fileRef, "")
+		if err != nil {
+			return nil, err
+		}
+		sidecarPath := filename + suffix
+		content, err := os.ReadFile(sidecarPath)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read sidecar annotation file %s: %w", sidecarPath, err)
+		}
+		var sidecar map[string]string
+		if err := json.Unmarshal(content, &sidecar); err != nil {
+			return nil, &oerrors.Error{
+				Err:            fmt.Errorf("invalid sidecar annotation file %s: %w", sidecarPath, err),
+				Recommendation: `Sidecar annotation file should be a JSON object of string values, e.g. {"key": "value"}`,
+			}
+		}
+		if len(sidecar) == 0 {
+			continue
+		}
+		if annotations == nil {
+			annotations = make(map[string]map[string]string)
+		}
+		fileAnnotations := annotations[filename]
+		if fileAnnotations == nil {
+			fileAnnotations = make(map[string]string)
+			annotations[filename] = fileAnnotations
+		}
+		for k, v := range sidecar {
+			if _, ok := fileAnnotations[k]; !ok {
+				fileAnnotations[k] = v
+			}
+		}
+	}
+	return annotations, nil
+}
+
+// Manifest annotations of the Git working tree an artifact is built from, in
+// addition to the standard revision and source annotations.
+const (
+	annotationGitBranch = "land.oras.git.branch"
+	annotationGitDirty  = "land.oras.git.dirty"
+)
+
+// addGitAnnotations adds the manifest annotations describing the Git working
+// tree at path. If lenient is true, a path which is not a Git working tree is
+// skipped.
+func addGitAnnotations(ctx context.Context, annotations map[string]map[string]string, path string, lenient bool) (map[string]map[string]string, error) {
+	metadata, err := git.ReadMetadata(ctx, path)
+	if err != nil {
+		if lenient && errors.Is(err, git.ErrNotRepository) {
+			return annotations, nil
+		}
+		return nil, &oerrors.Error{
+			Err:            fmt.Errorf("failed to read Git metadata: %w", err),
+			Recommendation: "Please make sure git is installed and the path is a Git working tree, or apply --git-annotations-lenient to skip",
+		}
+	}
+	generated := map[string]string{
+		ocispec.AnnotationRevision: metadata.Revision,
+		annotationGitDirty:         strconv.FormatBool(metadata.Dirty),
+	}
+	if metadata.Source != "" {
+		generated[ocispec.AnnotationSource] = metadata.Source
+	}
+	if metadata.Branch != "" {
+		generated[annotationGitBranch] = metadata.Branch
+	}
+	for _, key := range []string{ocispec.AnnotationRevision, ocispec.AnnotationSource, annotationGitBranch, annotationGitDirty} {
+		if value, ok := generated[key]; ok {
+			if annotations, err = setManifestAnnotation(annotations, key, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return annotations, nil
+}
+
+// reproducibleCreated is the created time of the manifest of reproducible
+// pushes, the Unix epoch.
+var reproducibleCreated = time.Unix(0, 0).UTC()
+
+// setReproducible sets the created time of the manifest to a fixed time unless
+// specified, and sorts layers by name so that the same files pushed in any
+// order produce the same manifest.
+func setReproducible(annotations map[string]map[string]string, layers []ocispec.Descriptor) (map[string]map[string]string, error) {
+	sort.SliceStable(layers, func(i, j int) bool {
+		return layers[i].Annotations[ocispec.AnnotationTitle] < layers[j].Annotations[ocispec.AnnotationTitle]
+	})
+	if _, ok := annotations[option.AnnotationManifest][ocispec.AnnotationCreated]; ok {
+		return annotations, nil
+	}
+	return setManifestAnnotation(annotations, ocispec.AnnotationCreated, reproducibleCreated.Format(time.RFC3339))
+}
+
+// setCreatedFromModTime sets the manifest annotation of the created time to
+// the latest modification time of the files in fileRefs, directories being
+// walked and stdin skipped. A created time specified by the user takes
+// precedence.
+func setCreatedFromModTime(annotations map[string]map[string]string, fileRefs []string) (map[string]map[string]string, error) {
+	if _, ok := annotations[option.AnnotationManifest][ocispec.AnnotationCreated]; ok {
+		return annotations, nil
+	}
+	var latest time.Time
+	for _, fileRef := range fileRefs {
+		if isStdinRef(fileRef) {
+			continue
+		}
+		filename, _, err := fileref.Parse(fileRef, "")
+		if err != nil {
+			return nil, err
+		}
+		if err := filepath.WalkDir(filename, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if modTime := info.ModTime(); modTime.After(latest) {
+				latest = modTime
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if latest.IsZero() {
+		// no file to push
+		return annotations, nil
+	}
+	return setManifestAnnotation(annotations, ocispec.AnnotationCreated, latest.UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,410 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras/cmd/oras/internal/display/status"
+	"oras.land/oras/cmd/oras/internal/option"
+	"oras.land/oras/cmd/oras/internal/output"
+)
+
+func Test_filterLayers(t *testing.T) {
+	layers := []ocispec.Descriptor{
+		{MediaType: "application/vnd.me.keep", Size: 1},
+		{MediaType: "application/vnd.me.drop", Size: 2},
+		{MediaType: "application/vnd.me.keep", Size: 3},
+	}
+	keep := func(desc ocispec.Descriptor) bool {
+		return desc.MediaType == "application/vnd.me.keep"
+	}
+	tests := []struct {
+		name    string
+		layers  []ocispec.Descriptor
+		keep    func(ocispec.Descriptor) bool
+		want    []ocispec.Descriptor
+		wantErr error
+	}{
+		{
+			name:   "nil filter",
+			layers: layers,
+			want:   layers,
+		},
+		{
+			name:   "no layer",
+			layers: nil,
+			keep:   keep,
+			want:   nil,
+		},
+		{
+			name:   "filter some layers",
+			layers: layers,
+			keep:   keep,
+			want:   []ocispec.Descriptor{layers[0], layers[2]},
+		},
+		{
+			name:    "filter all layers",
+			layers:  layers,
+			keep:    func(ocispec.Descriptor) bool { return false },
+			wantErr: errAllLayersFiltered,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filterLayers(tt.layers, tt.keep)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("filterLayers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterLayers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_applyPatternAnnotations(t *testing.T) {
+	layer := func(name string, annotations map[string]string) ocispec.Descriptor {
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[ocispec.AnnotationTitle] = name
+		return ocispec.Descriptor{Annotations: annotations}
+	}
+	layers := []ocispec.Descriptor{
+		layer("app.bin.sig", nil),
+		layer("docs/a/readme.md", nil),
+		layer("docs/b.sig", map[string]string{"kind": "exact"}),
+		layer("app.bin", nil),
+	}
+	annotations := map[string]map[string]string{
+		option.AnnotationManifest: {"kind": "manifest"},
+		"*.sig":                   {"kind": "signature"},
+		"docs/**":                 {"kind": "doc", "doc": "true"},
+	}
+	if err := applyPatternAnnotations(annotations, layers); err != nil {
+		t.Fatal("applyPatternAnnotations() error =", err)
+	}
+	want := []map[string]string{
+		{"kind": "signature"},
+		{"kind": "doc", "doc": "true"},
+		{"kind": "exact", "doc": "true"},
+		{},
+	}
+	for i, layer := range layers {
+		delete(layer.Annotations, ocispec.AnnotationTitle)
+		if !reflect.DeepEqual(layer.Annotations, want[i]) {
+			t.Errorf("applyPatternAnnotations() annotations of layer %d = %v, want %v", i, layer.Annotations, want[i])
+		}
+	}
+
+	if err := applyPatternAnnotations(map[string]map[string]string{"!*.sig": {}}, layers); err == nil {
+		t.Error("applyPatternAnnotations() with a negated pattern error = nil, want error")
+	}
+}
+
+func Test_setManifestAnnotation(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]map[string]string
+		want        map[string]map[string]string
+		wantErr     bool
+	}{
+		{
+			name: "no annotation",
+			want: map[string]map[string]string{
+				option.AnnotationManifest: {"key": "val"},
+			},
+		},
+		{
+			name: "merge with existing annotations",
+			annotations: map[string]map[string]string{
+				option.AnnotationConfig:   {"config": "val"},
+				option.AnnotationManifest: {"other": "val"},
+			},
+			want: map[string]map[string]string{
+				option.AnnotationConfig:   {"config": "val"},
+				option.AnnotationManifest: {"other": "val", "key": "val"},
+			},
+		},
+		{
+			name: "conflict with existing annotation",
+			annotations: map[string]map[string]string{
+				option.AnnotationManifest: {"key": "other"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := setManifestAnnotation(tt.annotations, "key", "val")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("setManifestAnnotation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("setManifestAnnotation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_loadSidecarAnnotations(t *testing.T) {
+	dir := t.TempDir()
+	withSidecar := filepath.Join(dir, "with-sidecar.bin")
+	withoutSidecar := filepath.Join(dir, "without-sidecar.bin")
+	malformed := filepath.Join(dir, "malformed.bin")
+	for path, content := range map[string]string{
+		withSidecar + ".annotations.json": `{"key": "sidecar", "other": "sidecar"}`,
+		malformed + ".annotations.json":   `{"key": 1}`,
+	} {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("load sidecar annotations", func(t *testing.T) {
+		annotations := map[string]map[string]string{
+			withSidecar: {"key": "file"},
+		}
+		got, err := loadSidecarAnnotations(annotations, []string{withSidecar + ":application/vnd.me", withoutSidecar}, ".annotations.json")
+		if err != nil {
+			t.Fatal("loadSidecarAnnotations() error =", err)
+		}
+		want := map[string]map[string]string{
+			withSidecar: {"key": "file", "other": "sidecar"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("loadSidecarAnnotations() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("malformed sidecar file", func(t *testing.T) {
+		_, err := loadSidecarAnnotations(nil, []string{malformed}, ".annotations.json")
+		if err == nil {
+			t.Fatal("loadSidecarAnnotations() error = nil, want error")
+		}
+		if want := malformed + ".annotations.json"; !strings.Contains(err.Error(), want) {
+			t.Errorf("loadSidecarAnnotations() error = %v, want error naming %s", err, want)
+		}
+	})
+
+	t.Run("stdin", func(t *testing.T) {
+		wd, err := os.Getwd()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chdir(dir); err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			_ = os.Chdir(wd)
+		}()
+		if err := os.WriteFile("-.annotations.json", []byte(`{"key": 1}`), 0600); err != nil {
+			t.Fatal(err)
+		}
+		got, err := loadSidecarAnnotations(nil, []string{"-:application/json"}, ".annotations.json")
+		if err != nil {
+			t.Fatal("loadSidecarAnnotations() error =", err)
+		}
+		if len(got) != 0 {
+			t.Errorf("loadSidecarAnnotations() = %v, want no annotations", got)
+		}
+	})
+}
+
+func Test_addGitAnnotations(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	annotations := map[string]map[string]string{
+		option.AnnotationManifest: {"key": "value"},
+	}
+	got, err := addGitAnnotations(ctx, annotations, dir, true)
+	if err != nil {
+		t.Fatal("addGitAnnotations() error =", err)
+	}
+	if !reflect.DeepEqual(got, annotations) {
+		t.Errorf("addGitAnnotations() = %v, want %v", got, annotations)
+	}
+	if _, err := addGitAnnotations(ctx, annotations, dir, false); err == nil {
+		t.Error("addGitAnnotations() error = nil, want error")
+	}
+}
+
+func Test_setCreatedFromModTime(t *testing.T) {
+	dir := t.TempDir()
+	older := filepath.Join(dir, "older.txt")
+	sub := filepath.Join(dir, "sub")
+	newer := filepath.Join(sub, "newer.txt")
+	if err := os.Mkdir(sub, 0700); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{older, newer} {
+		if err := os.WriteFile(path, []byte(path), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for path, modTime := range map[string]time.Time{
+		older: want.Add(-time.Hour),
+		newer: want,
+		sub:   want.Add(-time.Hour),
+		dir:   want.Add(-time.Hour),
+	} {
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := setCreatedFromModTime(nil, []string{older + ":text/plain", "-", dir})
+	if err != nil {
+		t.Fatal("setCreatedFromModTime() error =", err)
+	}
+	if created := got[option.AnnotationManifest][ocispec.AnnotationCreated]; created != want.Format(time.RFC3339) {
+		t.Errorf("created = %v, want %v", created, want.Format(time.RFC3339))
+	}
+
+	specified := map[string]map[string]string{
+		option.AnnotationManifest: {ocispec.AnnotationCreated: "2000-01-01T00:00:00Z"},
+	}
+	got, err = setCreatedFromModTime(specified, []string{older})
+	if err != nil {
+		t.Fatal("setCreatedFromModTime() error =", err)
+	}
+	if created := got[option.AnnotationManifest][ocispec.AnnotationCreated]; created != "2000-01-01T00:00:00Z" {
+		t.Errorf("created = %v, want the specified one", created)
+	}
+
+	if _, err := setCreatedFromModTime(nil, []string{filepath.Join(dir, "missing")}); err == nil {
+		t.Error("setCreatedFromModTime() error = nil, want error")
+	}
+}
+
+func Test_setReproducible(t *testing.T) {
+	layer := func(name string) ocispec.Descriptor {
+		return ocispec.Descriptor{Annotations: map[string]string{ocispec.AnnotationTitle: name}}
+	}
+	layers := []ocispec.Descriptor{layer("c"), layer("a"), layer("b")}
+	got, err := setReproducible(nil, layers)
+	if err != nil {
+		t.Fatal("setReproducible() error =", err)
+	}
+	if created := got[option.AnnotationManifest][ocispec.AnnotationCreated]; created != "1970-01-01T00:00:00Z" {
+		t.Errorf("created = %v, want 1970-01-01T00:00:00Z", created)
+	}
+	for i, want := range []string{"a", "b", "c"} {
+		if name := layers[i].Annotations[ocispec.AnnotationTitle]; name != want {
+			t.Errorf("layer %d name = %s, want %s", i, name, want)
+		}
+	}
+
+	specified := map[string]map[string]string{
+		option.AnnotationManifest: {ocispec.AnnotationCreated: "2000-01-01T00:00:00Z"},
+	}
+	got, err = setReproducible(specified, nil)
+	if err != nil {
+		t.Fatal("setReproducible() error =", err)
+	}
+	if created := got[option.AnnotationManifest][ocispec.AnnotationCreated]; created != "2000-01-01T00:00:00Z" {
+		t.Errorf("created = %v, want the specified time", created)
+	}
+}
+
+func Test_loadStdin(t *testing.T) {
+	ctx := context.Background()
+	store, err := file.New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	annotations := map[string]map[string]string{"report.json": {"foo": "bar"}}
+	printer := output.NewPrinter(io.Discard, io.Discard, false)
+
+	desc, err := loadStdin(ctx, store, nil, annotations, "-:application/json", "report.json", strings.NewReader(`{"a":1}`), filepath.Join(t.TempDir(), "stdin"), status.NewTextPushHandler(printer))
+	if err != nil {
+		t.Fatal("loadStdin() error =", err)
+	}
+	if desc.MediaType != "application/json" || desc.Annotations[ocispec.AnnotationTitle] != "report.json" || desc.Annotations["foo"] != "bar" {
+		t.Errorf("loadStdin() = %v, want report.json of type application/json annotated with foo=bar", desc)
+	}
+	got, err := content.FetchAll(ctx, store, desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != `{"a":1}` {
+		t.Errorf("stdin content = %q, want %q", got, `{"a":1}`)
+	}
+}
+
+func Test_loadFiles_onDuplicate(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hi.txt")
+	if err := os.WriteFile(path, []byte("hi"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	printer := output.NewPrinter(io.Discard, io.Discard, false)
+	tests := []struct {
+		onDuplicate string
+		want        []string
+		wantErr     bool
+	}{
+		{onDuplicate: onDuplicateError, wantErr: true},
+		{onDuplicate: onDuplicateSkip, want: []string{"hi.txt"}},
+		{onDuplicate: onDuplicateRename, want: []string{"hi.txt", "hi-1.txt", "hi-2.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.onDuplicate, func(t *testing.T) {
+			store, err := file.New(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer store.Close()
+			sources := make(fileSources)
+			descs, err := loadFiles(ctx, store, sources, nil, []string{"hi.txt", "./hi.txt", "hi.txt"}, tt.onDuplicate, status.NewTextPushHandler(printer))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, desc := range descs {
+				got = append(got, desc.Annotations[ocispec.AnnotationTitle])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadFiles() names = %v, want %v", got, tt.want)
+			}
+			for _, name := range got {
+				if src := sources[name]; filepath.Clean(src) != "hi.txt" {
+					t.Errorf("source of %s = %q, want hi.txt", name, src)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras/cmd/oras/internal/display/status"
+	"oras.land/oras/cmd/oras/internal/fileref"
+)
+
+// defaultIgnoreFile is the name of the file listing the patterns of the files
+// skipped when pushing the directory containing it.
+const defaultIgnoreFile = ".orasignore"
+
+// ignorePattern is a pattern of an ignore file, in the gitignore syntax.
+type ignorePattern struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// parseIgnoreFile reads the patterns of the ignore file at path.
+func parseIgnoreFile(path string) ([]ignorePattern, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []ignorePattern
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimRight(scanner.Text(), " \t")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pattern, err := compileIgnorePattern(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q at line %d of %s: %w", line, lineNum, path, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, scanner.Err()
+}
+
+// compileIgnorePattern compiles a line of an ignore file. Patterns without a
+// slash match names at any depth, "*" and "?" do not match "/", and "**"
+// matches any number of directories.
+func compileIgnorePattern(line string) (ignorePattern, error) {
+	var pattern ignorePattern
+	if strings.HasPrefix(line, "!") {
+		pattern.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return ignorePattern{}, errors.New("empty pattern")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case strings.HasPrefix(line[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(line[i:], "/**") && i+3 == len(line):
+			sb.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(line[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(line[i:], ']')
+			if end < 0 {
+				return ignorePattern{}, errors.New("unterminated character class")
+			}
+			class := line[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		case c == '\\' && i+1 < len(line):
+			i++
+			sb.WriteString(regexp.QuoteMeta(line[i : i+1]))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return ignorePattern{}, err
+	}
+	pattern.re = re
+	return pattern, nil
+}
+
+// compileExcludePatterns compiles the patterns of the --exclude flags.
+func compileExcludePatterns(lines []string) ([]ignorePattern, error) {
+	patterns := make([]ignorePattern, 0, len(lines))
+	for _, line := range lines {
+		pattern, err := compileIgnorePattern(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q for --exclude: %w", line, err)
+		}
+		if pattern.negate {
+			return nil, fmt.Errorf("invalid pattern %q for --exclude: negation is not supported", line)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// isIgnored returns true if the path relative to the pushed directory is
+// ignored by patterns, the last matching pattern taking precedence.
+func isIgnored(patterns []ignorePattern, rel string, isDir bool) bool {
+	ignored := false
+	for _, pattern := range patterns {
+		if pattern.dirOnly && !isDir {
+			continue
+		}
+		if pattern.re.MatchString(rel) {
+			ignored = !pattern.negate
+		}
+	}
+	return ignored
+}
+
+// copyIgnoring copies the directory src to dst, skipping the files and the
+// directories ignored by patterns. Files are hard linked when possible, and
+// their modes and modification times are kept.
+func copyIgnoring(src, dst string, patterns []ignorePattern) error {
+	type dirTime struct {
+		path string
+		info fs.FileInfo
+	}
+	var dirs []dirTime
+	if err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if rel != "." && isIgnored(patterns, filepath.ToSlash(rel), d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case d.IsDir():
+			if err := os.MkdirAll(target, info.Mode().Perm()|0700); err != nil {
+				return err
+			}
+			dirs = append(dirs, dirTime{path: target, info: info})
+			return nil
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			if os.Link(path, target) == nil {
+				return nil
+			}
+			return copyFile(target, path, info)
+		}
+		// other file types are not supported by tarballs
+		return nil
+	}); err != nil {
+		return err
+	}
+	// directory times are set after their content is written
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chmod(dirs[i].path, dirs[i].info.Mode().Perm()); err != nil {
+			return err
+		}
+		if err := os.Chtimes(dirs[i].path, dirs[i].info.ModTime(), dirs[i].info.ModTime()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFile copies the regular file src to dst, keeping its mode and
+// modification time.
+func copyFile(dst, src string, info fs.FileInfo) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
+// loadIgnoringDir adds the directory of fileRef, if it contains ignoreFile or
+// excludes is not empty, to store without the files ignored by the patterns
+// of ignoreFile followed by excludes. The kept files are copied to path. ok
+// is false if fileRef is not such a directory.
+func loadIgnoringDir(ctx context.Context, store *file.Store, sources fileSources, annotations map[string]map[string]string, fileRef string, ignoreFile string, excludes []ignorePattern, path string, displayStatus status.PushHandler) (desc ocispec.Descriptor, ok bool, err error) {
+	filename, mediaType, err := fileref.Parse(fileRef, "")
+	if err != nil {
+		return ocispec.Descriptor{}, false, err
+	}
+	if info, err := os.Stat(filename); err != nil || !info.IsDir() {
+		return ocispec.Descriptor{}, false, nil
+	}
+	var patterns []ignorePattern
+	if ignoreFile != "" {
+		patterns, err = parseIgnoreFile(filepath.Join(filename, ignoreFile))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return ocispec.Descriptor{}, false, err
+		}
+	}
+	patterns = append(patterns, excludes...)
+	if len(patterns) == 0 {
+		return ocispec.Descriptor{}, false, nil
+	}
+
+	name := filepath.Clean(filename)
+	if !filepath.IsAbs(name) {
+		name = filepath.ToSlash(name)
+	}
+	if err := displayStatus.OnFileLoading(name); err != nil {
+		return ocispec.Descriptor{}, false, err
+	}
+	if err := copyIgnoring(filename, path, patterns); err != nil {
+		return ocispec.Descriptor{}, false, err
+	}
+	dir, err := addFile(ctx, store, sources, name, mediaType, path)
+	if err != nil {
+		return ocispec.Descriptor{}, false, err
+	}
+	for k, v := range annotations[filename] {
+		dir.Annotations[k] = v
+	}
+	return dir, true, nil
+}
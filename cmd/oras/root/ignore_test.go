@@ -0,0 +1,106 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func Test_isIgnored(t *testing.T) {
+	var patterns []ignorePattern
+	for _, line := range []string{"*.log", "build/", "/secret.txt", "docs/**/*.tmp", "!keep.log", "a?c", `\!bang`} {
+		pattern, err := compileIgnorePattern(line)
+		if err != nil {
+			t.Fatalf("compileIgnorePattern(%q) error = %v", line, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{rel: "app.log", want: true},
+		{rel: "sub/app.log", want: true},
+		{rel: "keep.log", want: false},
+		{rel: "build", isDir: true, want: true},
+		{rel: "sub/build", isDir: true, want: true},
+		{rel: "build", isDir: false, want: false},
+		{rel: "secret.txt", want: true},
+		{rel: "sub/secret.txt", want: false},
+		{rel: "docs/a.tmp", want: true},
+		{rel: "docs/x/y/a.tmp", want: true},
+		{rel: "a.tmp", want: false},
+		{rel: "abc", want: true},
+		{rel: "a/c", want: false},
+		{rel: "!bang", want: true},
+		{rel: "main.go", want: false},
+	}
+	for _, tt := range tests {
+		if got := isIgnored(patterns, tt.rel, tt.isDir); got != tt.want {
+			t.Errorf("isIgnored(%q, %v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+
+	if _, err := compileIgnorePattern("[a-"); err == nil {
+		t.Error("compileIgnorePattern() with an unterminated class error = nil, want error")
+	}
+}
+
+func Test_copyIgnoring(t *testing.T) {
+	src := t.TempDir()
+	for _, name := range []string{"index.html", "debug.log", "node_modules/lib.js", "css/site.css"} {
+		path := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(src, defaultIgnoreFile), []byte("# build outputs\n*.log\nnode_modules/\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	patterns, err := parseIgnoreFile(filepath.Join(src, defaultIgnoreFile))
+	if err != nil {
+		t.Fatal("parseIgnoreFile() error =", err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "site")
+	if err := copyIgnoring(src, dst, patterns); err != nil {
+		t.Fatal("copyIgnoring() error =", err)
+	}
+	var got []string
+	if err := filepath.Walk(dst, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dst, path)
+		got = append(got, filepath.ToSlash(rel))
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	want := []string{defaultIgnoreFile, "css/site.css", "index.html"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("copied files = %v, want %v", got, want)
+	}
+}
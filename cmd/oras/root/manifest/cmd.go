@@ -17,6 +17,7 @@ package manifest
 
 import (
 	"github.com/spf13/cobra"
+	"oras.land/oras/cmd/oras/root/manifest/index"
 )
 
 func Cmd() *cobra.Command {
@@ -29,7 +30,9 @@ func Cmd() *cobra.Command {
 		deleteCmd(),
 		fetchCmd(),
 		fetchConfigCmd(),
+		index.Cmd(),
 		pushCmd(),
+		retagCmd(),
 	)
 	return cmd
 }
@@ -16,18 +16,31 @@ limitations under the License.
 package manifest
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"strings"
 
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote"
 	"oras.land/oras-go/v2/registry/remote/auth"
 	"oras.land/oras/cmd/oras/internal/argument"
 	"oras.land/oras/cmd/oras/internal/command"
 	oerrors "oras.land/oras/cmd/oras/internal/errors"
 	"oras.land/oras/cmd/oras/internal/option"
+	"oras.land/oras/internal/descriptor"
 	"oras.land/oras/internal/registryutil"
+	"oras.land/oras/internal/repository"
 )
 
 type deleteOptions struct {
@@ -36,6 +49,20 @@ type deleteOptions struct {
 	option.Descriptor
 	option.Pretty
 	option.Target
+
+	concurrency      int
+	autoConfirmBelow int64
+	emitScript       bool
+	protectFile      string
+	ignoreMissing    bool
+	preDeleteHook    string
+	// protected maps the digests loaded from protectFile to the rules
+	// protecting them.
+	protected map[digest.Digest]string
+	// hostname and namespace are set when deleting across all repositories
+	// under a namespace via `<registry>/<namespace>/*`.
+	hostname  string
+	namespace string
 }
 
 func deleteCmd() *cobra.Command {
@@ -57,6 +84,27 @@ Example - Delete a manifest and print its descriptor:
 
 Example - Delete a manifest by digest 'sha256:99e4703fbf30916f549cd6bfa9cdbab614b5392fbe64fdee971359a77073cdf9' from repository 'localhost:5000/hello':
   oras manifest delete localhost:5000/hello@sha:99e4703fbf30916f549cd6bfa9cdbab614b5392fbe64fdee971359a77073cdf9
+
+Example - [Preview] Delete a manifest without prompting confirmation if it is smaller than 1024 bytes:
+  oras manifest delete --auto-confirm-below 1024 localhost:5000/hello:v1
+
+Example - [Preview] Delete all tagged manifests of all repositories under the namespace 'localhost:5000/example-namespace':
+  oras manifest delete --force localhost:5000/example-namespace/*
+
+Example - [Preview] Delete a manifest with confirmation, succeeding if it is already gone:
+  oras manifest delete --ignore-missing localhost:5000/hello:v1
+
+Example - [Preview] Delete a manifest unless its digest is listed in the file 'protected.txt':
+  oras manifest delete --protect-file protected.txt localhost:5000/hello:v1
+
+Example - [Preview] Delete a manifest only if the script 'check-in-use.sh' exits with status 0 for it:
+  oras manifest delete --pre-delete-hook ./check-in-use.sh localhost:5000/hello:v1
+
+Example - [Preview] Print the commands deleting a manifest by its resolved digest instead of deleting it:
+  oras manifest delete --emit-script localhost:5000/hello:v1
+
+Example - [Preview] Print the commands deleting all tagged manifests under the namespace 'localhost:5000/example-namespace':
+  oras manifest delete --emit-script localhost:5000/example-namespace/*
 `,
 		Args: oerrors.CheckArgs(argument.Exactly(1), "the manifest to delete"),
 		PreRunE: func(cmd *cobra.Command, args []string) error {
@@ -64,13 +112,40 @@ Example - Delete a manifest by digest 'sha256:99e4703fbf30916f549cd6bfa9cdbab614
 			if opts.OutputDescriptor && !opts.Force {
 				return errors.New("must apply --force to confirm the deletion if the descriptor is outputted")
 			}
+			if err := oerrors.CheckMutuallyExclusiveFlags(cmd.Flags(), "emit-script", "descriptor"); err != nil {
+				return err
+			}
+			if opts.autoConfirmBelow < 0 {
+				return fmt.Errorf("invalid value %d for --auto-confirm-below: must not be negative", opts.autoConfirmBelow)
+			}
+			if namespace, ok := strings.CutSuffix(args[0], "/*"); ok {
+				if err := opts.parseNamespace(namespace); err != nil {
+					return err
+				}
+				opts.RawReference = namespace
+			}
+			if opts.protectFile != "" {
+				var err error
+				if opts.protected, err = loadProtectedDigests(opts.protectFile); err != nil {
+					return err
+				}
+			}
 			return option.Parse(cmd, &opts)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.namespace != "" {
+				return deleteNamespace(cmd, &opts)
+			}
 			return deleteManifest(cmd, &opts)
 		},
 	}
 
+	cmd.Flags().IntVarP(&opts.concurrency, "concurrency", "", 3, "[Preview] concurrency level for deleting across repositories")
+	cmd.Flags().BoolVarP(&opts.emitScript, "emit-script", "", false, "[Preview] print the commands deleting the resolved manifests by digest instead of deleting them")
+	cmd.Flags().BoolVarP(&opts.ignoreMissing, "ignore-missing", "", false, "[Preview] succeed if the manifest does not exist, without requiring --force")
+	cmd.Flags().StringVarP(&opts.preDeleteHook, "pre-delete-hook", "", "", "[Preview] `path` of an executable run with the reference and the digest of each manifest before deleting it, a non-zero exit status aborts the deletion")
+	cmd.Flags().StringVarP(&opts.protectFile, "protect-file", "", "", "[Preview] `path` of a file listing the digests of manifests that must never be deleted, one per line")
+	cmd.Flags().Int64VarP(&opts.autoConfirmBelow, "auto-confirm-below", "", 0, "[Preview] skip the confirmation prompt if the manifest is smaller than `bytes`")
 	opts.EnableDistributionSpecFlag()
 	option.ApplyFlags(&opts, cmd.Flags())
 	return oerrors.Command(cmd, &opts.Target)
@@ -96,38 +171,354 @@ func deleteManifest(cmd *cobra.Command, opts *deleteOptions) error {
 	desc, err := manifests.Resolve(ctx, opts.Reference)
 	if err != nil {
 		if errors.Is(err, errdef.ErrNotFound) {
-			if opts.Force && !opts.OutputDescriptor {
+			if opts.ignoreMissing || (opts.Force && !opts.OutputDescriptor) {
 				// ignore nonexistent
-				_ = opts.Println("Missing", opts.RawReference)
+				if !opts.OutputDescriptor {
+					_ = opts.Println("Missing", opts.RawReference)
+				}
 				return nil
 			}
 			return fmt.Errorf("%s: the specified manifest does not exist", opts.RawReference)
 		}
 		return err
 	}
-
-	prompt := fmt.Sprintf("Are you sure you want to delete the manifest %q and all tags associated with it?", desc.Digest)
-	confirmed, err := opts.AskForConfirmation(os.Stdin, prompt)
-	if err != nil {
+	if err := opts.checkProtected(opts.RawReference, desc.Digest); err != nil {
 		return err
 	}
-	if !confirmed {
-		return nil
+	if opts.emitScript {
+		return opts.Println(opts.deleteCommand(opts.Path, desc.Digest))
+	}
+	if err := opts.runPreDeleteHook(ctx, opts.RawReference, desc); err != nil {
+		return err
 	}
 
+	if opts.needsConfirmation(desc.Size) {
+		prompt := fmt.Sprintf("Are you sure you want to delete the manifest %q and all tags associated with it?", desc.Digest)
+		confirmed, err := opts.AskForConfirmation(os.Stdin, prompt)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return nil
+		}
+	}
+
+	// track the referrers index possibly updated along with the deletion
+	subject, referrersIndex, err := resolveReferrersIndex(ctx, manifests, desc)
+	if err != nil {
+		return err
+	}
 	if err = manifests.Delete(ctx, desc); err != nil {
 		return fmt.Errorf("failed to delete %s: %w", opts.RawReference, err)
 	}
+	var updatedIndex *ocispec.Descriptor
+	indexUpdated := false
+	if referrersIndex != nil {
+		if updatedIndex, indexUpdated, err = resolveReferrersIndexUpdate(ctx, manifests, *subject, *referrersIndex); err != nil {
+			return err
+		}
+	}
 
 	if opts.OutputDescriptor {
-		descJSON, err := opts.Marshal(desc)
+		deleted := deletedManifest{Descriptor: desc}
+		if indexUpdated {
+			if updatedIndex != nil {
+				deleted.ReferrersIndex = updatedIndex
+			} else {
+				deleted.DeletedReferrersIndex = referrersIndex
+			}
+		}
+		descJSON, err := json.Marshal(deleted)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to marshal descriptor: %w", err)
 		}
 		return opts.Output(os.Stdout, descJSON)
 	}
 
 	_ = opts.Println("Deleted", opts.AnnotatedReference())
+	if indexUpdated {
+		_ = opts.Println(describeReferrersIndexUpdate(*subject, *referrersIndex, updatedIndex))
+	}
 
 	return nil
 }
+
+// deletedManifest is the descriptor output of a deleted manifest, along with
+// the referrers index of its subject if updated or deleted by the deletion.
+type deletedManifest struct {
+	ocispec.Descriptor
+	ReferrersIndex        *ocispec.Descriptor `json:"referrersIndex,omitempty"`
+	DeletedReferrersIndex *ocispec.Descriptor `json:"deletedReferrersIndex,omitempty"`
+}
+
+// needsConfirmation reports whether the deletion of a manifest of size bytes
+// is prompted, which is skipped below --auto-confirm-below.
+func (opts *deleteOptions) needsConfirmation(size int64) bool {
+	return size >= opts.autoConfirmBelow
+}
+
+// resolveReferrersIndex resolves the subject of the manifest desc and the
+// referrers index of the subject under the referrers tag schema. Nil is
+// returned for the ones not found.
+func resolveReferrersIndex(ctx context.Context, manifests option.ResolvableDeleter, desc ocispec.Descriptor) (subject *ocispec.Descriptor, index *ocispec.Descriptor, err error) {
+	fetcher, ok := manifests.(content.Fetcher)
+	if !ok || !descriptor.IsManifest(desc) {
+		return nil, nil, nil
+	}
+	manifestBytes, err := content.FetchAll(ctx, fetcher, desc)
+	if err != nil {
+		return nil, nil, err
+	}
+	var manifest struct {
+		Subject *ocispec.Descriptor `json:"subject,omitempty"`
+	}
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil || manifest.Subject == nil {
+		return nil, nil, nil
+	}
+	indexDesc, err := manifests.Resolve(ctx, registryutil.ReferrersTag(*manifest.Subject))
+	if err != nil {
+		if errors.Is(err, errdef.ErrNotFound) {
+			return manifest.Subject, nil, nil
+		}
+		return nil, nil, err
+	}
+	return manifest.Subject, &indexDesc, nil
+}
+
+// resolveReferrersIndexUpdate resolves the referrers index of subject after
+// the deletion of one of its referrers. updated is false if the index is
+// still before, and after is nil if the index has been deleted.
+func resolveReferrersIndexUpdate(ctx context.Context, resolver content.Resolver, subject ocispec.Descriptor, before ocispec.Descriptor) (after *ocispec.Descriptor, updated bool, err error) {
+	desc, err := resolver.Resolve(ctx, registryutil.ReferrersTag(subject))
+	if err != nil {
+		if errors.Is(err, errdef.ErrNotFound) {
+			return nil, true, nil
+		}
+		return nil, false, err
+	}
+	if desc.Digest == before.Digest {
+		return &desc, false, nil
+	}
+	return &desc, true, nil
+}
+
+// describeReferrersIndexUpdate describes how the referrers index of subject
+// has changed from before to after, nil if it has been deleted.
+func describeReferrersIndexUpdate(subject ocispec.Descriptor, before ocispec.Descriptor, after *ocispec.Descriptor) string {
+	if after == nil {
+		return fmt.Sprintf("Deleted referrers index %s of %s", before.Digest, subject.Digest)
+	}
+	return fmt.Sprintf("Updated referrers index of %s to %s", subject.Digest, after.Digest)
+}
+
+// deleteCommand returns the command deleting the manifest of dgst in the
+// repository or the OCI image layout at path, connecting to the registry
+// as the current command does.
+func (opts *deleteOptions) deleteCommand(path string, dgst digest.Digest) string {
+	args := []string{"oras", "manifest", "delete"}
+	if opts.IsOCILayout {
+		args = append(args, "--oci-layout")
+	} else {
+		args = append(args, opts.ConnectionArgs()...)
+	}
+	if spec := opts.DistributionSpec.String(); spec != "" {
+		args = append(args, "--distribution-spec", spec)
+	}
+	args = append(args, fmt.Sprintf("%s@%s", path, dgst), "--force")
+	for i, arg := range args {
+		args[i] = shellQuote(arg)
+	}
+	return strings.Join(args, " ")
+}
+
+// shellQuote quotes s for a POSIX shell, unless it consists only of
+// characters which are not special to the shell.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789@%+=:,./_-") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// loadProtectedDigests reads the digests listed in the file at path, one per
+// line. Empty lines and lines starting with '#' are ignored.
+func loadProtectedDigests(path string) (map[digest.Digest]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the protect file: %w", err)
+	}
+	defer f.Close()
+
+	protected := make(map[digest.Digest]string)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		dgst, err := digest.Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid digest %q: %w", path, line, text, err)
+		}
+		protected[dgst] = fmt.Sprintf("%s:%d", path, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the protect file: %w", err)
+	}
+	return protected, nil
+}
+
+// checkProtected returns an error if the manifest of dgst resolved from
+// reference is protected from deletion.
+func (opts *deleteOptions) checkProtected(reference string, dgst digest.Digest) error {
+	if rule, ok := opts.protected[dgst]; ok {
+		return &oerrors.Error{
+			Err:            fmt.Errorf("refusing to delete %s: the manifest %s is protected by %s", reference, dgst, rule),
+			Recommendation: fmt.Sprintf("Remove the digest from %s if the manifest should be deleted", opts.protectFile),
+		}
+	}
+	return nil
+}
+
+// runPreDeleteHook runs the pre-delete hook, if any, for the manifest desc
+// resolved from reference. The deletion must be aborted if an error is
+// returned.
+func (opts *deleteOptions) runPreDeleteHook(ctx context.Context, reference string, desc ocispec.Descriptor) error {
+	if opts.preDeleteHook == "" {
+		return nil
+	}
+	hook := exec.CommandContext(ctx, opts.preDeleteHook, reference, desc.Digest.String())
+	hook.Env = append(os.Environ(),
+		"ORAS_DELETE_REFERENCE="+reference,
+		"ORAS_DELETE_DIGEST="+desc.Digest.String(),
+		"ORAS_DELETE_MEDIA_TYPE="+desc.MediaType,
+	)
+	hook.Stdout = os.Stderr
+	hook.Stderr = os.Stderr
+	if err := hook.Run(); err != nil {
+		return &oerrors.Error{
+			Err:            fmt.Errorf("deletion of %s@%s aborted by the pre-delete hook: %w", reference, desc.Digest, err),
+			Recommendation: fmt.Sprintf("Check the output of %s for why the manifest must not be deleted", opts.preDeleteHook),
+		}
+	}
+	return nil
+}
+
+// parseNamespace validates the options for deleting across all repositories
+// under namespace.
+func (opts *deleteOptions) parseNamespace(namespace string) error {
+	switch {
+	case opts.IsOCILayout:
+		return errors.New("deleting across repositories is not supported for an OCI image layout")
+	case !opts.Force && !opts.emitScript:
+		return errors.New("must apply --force to delete manifests across repositories")
+	case opts.OutputDescriptor:
+		return errors.New("--descriptor cannot be used when deleting manifests across repositories")
+	case opts.concurrency < 1:
+		return fmt.Errorf("invalid concurrency level %d: must be positive", opts.concurrency)
+	}
+	var err error
+	if opts.hostname, opts.namespace, err = repository.ParseRepoPath(namespace); err != nil {
+		return fmt.Errorf("could not parse namespace %q: %w", namespace, err)
+	}
+	if opts.namespace == "" {
+		return fmt.Errorf("%q: a namespace must be specified to delete manifests across repositories", namespace)
+	}
+	return nil
+}
+
+func deleteNamespace(cmd *cobra.Command, opts *deleteOptions) error {
+	ctx, logger := command.GetLogger(cmd, &opts.Common)
+	reg, err := opts.NewRegistry(opts.hostname, opts.Common, logger)
+	if err != nil {
+		return err
+	}
+	var repos []string
+	if err := reg.Repositories(ctx, "", func(names []string) error {
+		for _, name := range names {
+			if strings.HasPrefix(name, opts.namespace) {
+				repos = append(repos, name)
+			}
+		}
+		return nil
+	}); err != nil {
+		return &oerrors.Error{
+			Err:            fmt.Errorf("could not list repositories for %q with prefix %q: %w", reg.Reference.Host(), opts.namespace, err),
+			Recommendation: "Please make sure the registry supports the catalog API",
+		}
+	}
+	if len(repos) == 0 {
+		_ = opts.Println("No repository found under", opts.RawReference)
+		return nil
+	}
+
+	if !opts.emitScript {
+		namespace := strings.TrimSuffix(opts.namespace, "/")
+		prompt := fmt.Sprintf("Are you sure you want to delete all manifests in %d repositories under %q? Type the namespace %q to confirm:", len(repos), opts.RawReference, namespace)
+		confirmed, err := opts.AskForTypedConfirmation(os.Stdin, prompt, namespace)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return nil
+		}
+	}
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(opts.concurrency)
+	for _, name := range repos {
+		eg.Go(func() error {
+			repo, err := opts.NewRepository(opts.hostname+"/"+name, opts.Common, logger)
+			if err != nil {
+				return err
+			}
+			return deleteRepositoryManifests(egCtx, repo, opts, logger)
+		})
+	}
+	return eg.Wait()
+}
+
+// deleteRepositoryManifests deletes the manifests of all tags in repo.
+func deleteRepositoryManifests(ctx context.Context, repo *remote.Repository, opts *deleteOptions, logger logrus.FieldLogger) error {
+	ctx = registryutil.WithScopeHint(ctx, repo, auth.ActionPull, auth.ActionPush, auth.ActionDelete)
+	var tags []string
+	if err := repo.Tags(ctx, "", func(t []string) error {
+		tags = append(tags, t...)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to list tags of %s: %w", repo.Reference, err)
+	}
+
+	deleted := make(map[digest.Digest]bool)
+	for _, tag := range tags {
+		desc, err := repo.Resolve(ctx, tag)
+		if err != nil {
+			if errors.Is(err, errdef.ErrNotFound) {
+				// removed along with a previously deleted manifest
+				logger.Debugf("tag %s of %s is gone, skipped", tag, repo.Reference)
+				continue
+			}
+			return err
+		}
+		if deleted[desc.Digest] {
+			continue
+		}
+		if err := opts.checkProtected(fmt.Sprintf("%s:%s", repo.Reference, tag), desc.Digest); err != nil {
+			return err
+		}
+		if opts.emitScript {
+			deleted[desc.Digest] = true
+			_ = opts.Println(opts.deleteCommand(repo.Reference.String(), desc.Digest))
+			continue
+		}
+		if err := opts.runPreDeleteHook(ctx, fmt.Sprintf("%s:%s", repo.Reference, tag), desc); err != nil {
+			return err
+		}
+		if err := repo.Manifests().Delete(ctx, desc); err != nil {
+			return fmt.Errorf("failed to delete %s@%s: %w", repo.Reference, desc.Digest, err)
+		}
+		deleted[desc.Digest] = true
+		_ = opts.Println("Deleted", fmt.Sprintf("[%s] %s@%s", opts.Type, repo.Reference, desc.Digest))
+	}
+	return nil
+}
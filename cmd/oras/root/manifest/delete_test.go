@@ -0,0 +1,330 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras/cmd/oras/internal/option"
+	"oras.land/oras/internal/registryutil"
+)
+
+func Test_deleteOptions_parseNamespace(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          deleteOptions
+		namespace     string
+		wantHostname  string
+		wantNamespace string
+		wantErr       bool
+	}{
+		{
+			name:          "valid namespace",
+			opts:          deleteOptions{Confirmation: option.Confirmation{Force: true}, concurrency: 3},
+			namespace:     "localhost:5000/team/project",
+			wantHostname:  "localhost:5000",
+			wantNamespace: "team/project/",
+		},
+		{
+			name:      "no namespace",
+			opts:      deleteOptions{Confirmation: option.Confirmation{Force: true}, concurrency: 3},
+			namespace: "localhost:5000",
+			wantErr:   true,
+		},
+		{
+			name:      "no force",
+			opts:      deleteOptions{concurrency: 3},
+			namespace: "localhost:5000/team",
+			wantErr:   true,
+		},
+		{
+			name:      "invalid concurrency",
+			opts:      deleteOptions{Confirmation: option.Confirmation{Force: true}},
+			namespace: "localhost:5000/team",
+			wantErr:   true,
+		},
+		{
+			name:      "oci layout",
+			opts:      deleteOptions{Confirmation: option.Confirmation{Force: true}, Target: option.Target{IsOCILayout: true}, concurrency: 3},
+			namespace: "layout/team",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			err := opts.parseNamespace(tt.namespace)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseNamespace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if opts.hostname != tt.wantHostname {
+				t.Errorf("parseNamespace() hostname = %v, want %v", opts.hostname, tt.wantHostname)
+			}
+			if opts.namespace != tt.wantNamespace {
+				t.Errorf("parseNamespace() namespace = %v, want %v", opts.namespace, tt.wantNamespace)
+			}
+		})
+	}
+}
+
+func Test_deleteOptions_deleteCommand(t *testing.T) {
+	dgst := digest.FromString("test")
+	opts := deleteOptions{}
+	want := "oras manifest delete localhost:5000/hello@" + dgst.String() + " --force"
+	if got := opts.deleteCommand("localhost:5000/hello", dgst); got != want {
+		t.Errorf("deleteCommand() = %v, want %v", got, want)
+	}
+
+	opts.IsOCILayout = true
+	if err := opts.DistributionSpec.Set(option.DistributionSpecReferrersTagV1_1); err != nil {
+		t.Fatal(err)
+	}
+	want = "oras manifest delete --oci-layout --distribution-spec v1.1-referrers-tag layout@" + dgst.String() + " --force"
+	if got := opts.deleteCommand("layout", dgst); got != want {
+		t.Errorf("deleteCommand() = %v, want %v", got, want)
+	}
+	want = "oras manifest delete --oci-layout --distribution-spec v1.1-referrers-tag 'my layout/it'\\''s@" + dgst.String() + "' --force"
+	if got := opts.deleteCommand("my layout/it's", dgst); got != want {
+		t.Errorf("deleteCommand() = %v, want %v", got, want)
+	}
+
+	cmd := &cobra.Command{}
+	opts = deleteOptions{}
+	option.ApplyFlags(&opts, cmd.Flags())
+	for name, value := range map[string]string{"plain-http": "true", "insecure": "true", "password": "secret"} {
+		if err := cmd.Flags().Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want = "oras manifest delete --insecure --plain-http=true localhost:5000/hello@" + dgst.String() + " --force"
+	if got := opts.deleteCommand("localhost:5000/hello", dgst); got != want {
+		t.Errorf("deleteCommand() = %v, want %v", got, want)
+	}
+}
+
+func Test_shellQuote(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{arg: "localhost:5000/hello@sha256:abc", want: "localhost:5000/hello@sha256:abc"},
+		{arg: "--plain-http=true", want: "--plain-http=true"},
+		{arg: "", want: "''"},
+		{arg: "my layout", want: "'my layout'"},
+		{arg: "it's", want: `'it'\''s'`},
+		{arg: "$HOME;rm", want: "'$HOME;rm'"},
+	}
+	for _, tt := range tests {
+		if got := shellQuote(tt.arg); got != tt.want {
+			t.Errorf("shellQuote(%q) = %s, want %s", tt.arg, got, tt.want)
+		}
+	}
+}
+
+func Test_deleteOptions_needsConfirmation(t *testing.T) {
+	tests := []struct {
+		name             string
+		autoConfirmBelow int64
+		size             int64
+		want             bool
+	}{
+		{name: "disabled", autoConfirmBelow: 0, size: 0, want: true},
+		{name: "below", autoConfirmBelow: 1024, size: 1023, want: false},
+		{name: "equal", autoConfirmBelow: 1024, size: 1024, want: true},
+		{name: "above", autoConfirmBelow: 1024, size: 1025, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := deleteOptions{autoConfirmBelow: tt.autoConfirmBelow}
+			if got := opts.needsConfirmation(tt.size); got != tt.want {
+				t.Errorf("needsConfirmation(%d) = %v, want %v", tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_deleteManifest_ignoreMissing(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := oci.New(dir); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "missing", args: []string{"--oci-layout", dir + ":missing"}, wantErr: true},
+		{name: "ignore missing", args: []string{"--oci-layout", "--ignore-missing", dir + ":missing"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := deleteCmd()
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			if err := cmd.ExecuteContext(context.Background()); (err != nil) != tt.wantErr {
+				t.Errorf("delete %v error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_loadProtectedDigests(t *testing.T) {
+	dgst := digest.FromString("test")
+	path := filepath.Join(t.TempDir(), "protected.txt")
+	if err := os.WriteFile(path, []byte("# base images\n\n"+dgst.String()+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadProtectedDigests(path)
+	if err != nil {
+		t.Fatal("loadProtectedDigests() error =", err)
+	}
+	want := map[digest.Digest]string{dgst: path + ":3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loadProtectedDigests() = %v, want %v", got, want)
+	}
+
+	opts := deleteOptions{protected: got}
+	if err := opts.checkProtected("localhost:5000/hello:v1", dgst); err == nil {
+		t.Error("checkProtected() error = nil, want error")
+	}
+	if err := opts.checkProtected("localhost:5000/hello:v2", digest.FromString("other")); err != nil {
+		t.Error("checkProtected() error =", err)
+	}
+
+	if err := os.WriteFile(path, []byte("latest\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadProtectedDigests(path); err == nil {
+		t.Error("loadProtectedDigests() error = nil, want error")
+	}
+}
+
+func Test_referrersIndex(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	push := func(mediaType string, b []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, b)
+		if err := store.Push(ctx, desc, bytes.NewReader(b)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	subject := push(ocispec.MediaTypeImageManifest, []byte(`{"schemaVersion":2}`))
+	subjectJSON, err := json.Marshal(subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	referrer := push(ocispec.MediaTypeImageManifest, []byte(`{"schemaVersion":2,"subject":`+string(subjectJSON)+`}`))
+	index := push(ocispec.MediaTypeImageIndex, []byte(`{"schemaVersion":2,"manifests":[]}`))
+	tag := registryutil.ReferrersTag(subject)
+	if err := store.Tag(ctx, index, tag); err != nil {
+		t.Fatal(err)
+	}
+
+	gotSubject, gotIndex, err := resolveReferrersIndex(ctx, deleter{store}, referrer)
+	if err != nil {
+		t.Fatal("resolveReferrersIndex() error =", err)
+	}
+	if gotSubject == nil || gotSubject.Digest != subject.Digest {
+		t.Errorf("resolveReferrersIndex() subject = %v, want %v", gotSubject, subject)
+	}
+	if gotIndex == nil || gotIndex.Digest != index.Digest {
+		t.Errorf("resolveReferrersIndex() index = %v, want %v", gotIndex, index)
+	}
+	if gotSubject, gotIndex, err = resolveReferrersIndex(ctx, deleter{store}, subject); err != nil || gotSubject != nil || gotIndex != nil {
+		t.Errorf("resolveReferrersIndex() = %v, %v, %v, want nil, nil, nil", gotSubject, gotIndex, err)
+	}
+
+	if got, updated, err := resolveReferrersIndexUpdate(ctx, store, subject, index); err != nil || updated || got == nil || got.Digest != index.Digest {
+		t.Errorf("resolveReferrersIndexUpdate() = %v, %v, %v, want the index not updated", got, updated, err)
+	}
+	newIndex := push(ocispec.MediaTypeImageIndex, []byte(`{"schemaVersion":2,"manifests":[{}]}`))
+	if err := store.Tag(ctx, newIndex, tag); err != nil {
+		t.Fatal(err)
+	}
+	got, updated, err := resolveReferrersIndexUpdate(ctx, store, subject, index)
+	if err != nil || !updated || got == nil || got.Digest != newIndex.Digest {
+		t.Fatalf("resolveReferrersIndexUpdate() = %v, %v, %v, want %v", got, updated, err, newIndex)
+	}
+	want := "Updated referrers index of " + subject.Digest.String() + " to " + newIndex.Digest.String()
+	if got := describeReferrersIndexUpdate(subject, index, got); got != want {
+		t.Errorf("describeReferrersIndexUpdate() = %q, want %q", got, want)
+	}
+	if got, updated, err = resolveReferrersIndexUpdate(ctx, store, referrer, index); err != nil || !updated || got != nil {
+		t.Fatalf("resolveReferrersIndexUpdate() = %v, %v, %v, want the index deleted", got, updated, err)
+	}
+	want = "Deleted referrers index " + index.Digest.String() + " of " + referrer.Digest.String()
+	if got := describeReferrersIndexUpdate(referrer, index, nil); got != want {
+		t.Errorf("describeReferrersIndexUpdate() = %q, want %q", got, want)
+	}
+}
+
+func Test_deletedManifest(t *testing.T) {
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, []byte(`{"schemaVersion":2}`))
+	index := content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, []byte(`{"schemaVersion":2,"manifests":[]}`))
+	descJSON, err := json.Marshal(desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(deletedManifest{Descriptor: desc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, descJSON) {
+		t.Errorf("deleted manifest = %s, want %s", got, descJSON)
+	}
+
+	got, err = json.Marshal(deletedManifest{Descriptor: desc, ReferrersIndex: &index})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var output struct {
+		Digest         digest.Digest       `json:"digest"`
+		ReferrersIndex *ocispec.Descriptor `json:"referrersIndex"`
+	}
+	if err := json.Unmarshal(got, &output); err != nil {
+		t.Fatal(err)
+	}
+	if output.Digest != desc.Digest || output.ReferrersIndex == nil || output.ReferrersIndex.Digest != index.Digest {
+		t.Errorf("deleted manifest = %s, want %s with the referrers index %s", got, desc.Digest, index.Digest)
+	}
+}
+
+// deleter is a memory store implementing option.ResolvableDeleter.
+type deleter struct {
+	*memory.Store
+}
+
+func (deleter) Delete(context.Context, ocispec.Descriptor) error {
+	return nil
+}
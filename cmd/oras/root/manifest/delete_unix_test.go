@@ -0,0 +1,56 @@
+//go:build freebsd || linux || netbsd || openbsd || solaris
+
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func Test_deleteOptions_runPreDeleteHook(t *testing.T) {
+	dir := t.TempDir()
+	protected := digest.FromString("protected")
+	hook := filepath.Join(dir, "hook.sh")
+	script := "#!/bin/sh\n" +
+		`[ "$2" = "$ORAS_DELETE_DIGEST" ] || exit 2` + "\n" +
+		`[ "$ORAS_DELETE_DIGEST" != "` + protected.String() + `" ]` + "\n"
+	if err := os.WriteFile(hook, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	opts := deleteOptions{preDeleteHook: hook}
+
+	desc := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromString("other")}
+	if err := opts.runPreDeleteHook(ctx, "localhost:5000/hello:v1", desc); err != nil {
+		t.Error("runPreDeleteHook() error =", err)
+	}
+	desc.Digest = protected
+	if err := opts.runPreDeleteHook(ctx, "localhost:5000/hello:v1", desc); err == nil {
+		t.Error("runPreDeleteHook() error = nil, want error")
+	}
+
+	opts.preDeleteHook = ""
+	if err := opts.runPreDeleteHook(ctx, "localhost:5000/hello:v1", desc); err != nil {
+		t.Error("runPreDeleteHook() error =", err)
+	}
+}
@@ -145,6 +145,9 @@ func fetchManifest(cmd *cobra.Command, opts *fetchOptions) (fetchErr error) {
 		// fetch manifest descriptor and content
 		fetchOpts := oras.DefaultFetchBytesOptions
 		fetchOpts.TargetPlatform = opts.Platform.Platform
+		if opts.MaxMetadataBytes > 0 {
+			fetchOpts.MaxBytes = opts.MaxMetadataBytes
+		}
 		desc, content, err = oras.FetchBytes(ctx, src, opts.Reference, fetchOpts)
 		if err != nil {
 			return fmt.Errorf("failed to fetch the content of %q: %w", opts.RawReference, err)
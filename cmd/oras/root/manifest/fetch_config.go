@@ -108,6 +108,9 @@ func fetchConfig(cmd *cobra.Command, opts *fetchConfigOptions) (fetchErr error)
 	if err != nil {
 		return err
 	}
+	if err := opts.CheckMetadataSize(configDesc); err != nil {
+		return err
+	}
 
 	if !opts.OutputDescriptor || opts.outputPath != "" {
 		// fetch config content
@@ -0,0 +1,174 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras/cmd/oras/internal/argument"
+	"oras.land/oras/cmd/oras/internal/command"
+	oerrors "oras.land/oras/cmd/oras/internal/errors"
+	"oras.land/oras/cmd/oras/internal/option"
+	"oras.land/oras/internal/descriptor"
+	"oras.land/oras/internal/registryutil"
+)
+
+type createOptions struct {
+	option.Common
+	option.Descriptor
+	option.Pretty
+	option.Target
+
+	sources []string
+}
+
+func createCmd() *cobra.Command {
+	var opts createOptions
+	cmd := &cobra.Command{
+		Use:   "create [flags] <name>[:<tag>] {<tag>|<digest>} [...]",
+		Short: "[Preview] Create and push an index from the manifests in a repository",
+		Long: `[Preview] Create and push an index from the manifests in a repository
+
+The platform of each manifest is read from its image config, so all the
+manifests must be image manifests with the platform populated in the config.
+
+Example - Create an index from the manifests tagged 'linux-amd64' and 'linux-arm64' in repository 'localhost:5000/hello' and tag it with 'v1':
+  oras manifest index create localhost:5000/hello:v1 linux-amd64 linux-arm64
+
+Example - Create an index from manifests referenced by digest and push it without tagging:
+  oras manifest index create localhost:5000/hello sha256:99e4703fbf30916f549cd6bfa9cdbab614b5392fbe64fdee971359a77073cdf9 sha256:0a9e0b8bd4c8e4a1bd76a7e1c2f6e0fc6ea3d2a9c6bea5d2c0e3fa0e5a8e3b34
+
+Example - Create an index and output its descriptor:
+  oras manifest index create --descriptor localhost:5000/hello:v1 linux-amd64 linux-arm64
+
+Example - Create an index in an OCI image layout folder 'layout-dir' and tag it with 'v1':
+  oras manifest index create --oci-layout layout-dir:v1 linux-amd64 linux-arm64
+`,
+		Args: oerrors.CheckArgs(argument.AtLeast(2), "the destination of the index and the manifests to include"),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			opts.RawReference = args[0]
+			opts.sources = args[1:]
+			opts.Verbose = opts.Verbose && !opts.OutputDescriptor
+			return option.Parse(cmd, &opts)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return createIndex(cmd, &opts)
+		},
+	}
+
+	option.ApplyFlags(&opts, cmd.Flags())
+	return oerrors.Command(cmd, &opts.Target)
+}
+
+func createIndex(cmd *cobra.Command, opts *createOptions) error {
+	ctx, logger := command.GetLogger(cmd, &opts.Common)
+	if strings.Contains(opts.Reference, ":") {
+		return fmt.Errorf("%s: the index can only be tagged, not referenced by digest", opts.RawReference)
+	}
+	target, err := opts.NewTarget(opts.Common, logger)
+	if err != nil {
+		return err
+	}
+	ctx = registryutil.WithScopeHint(ctx, target, auth.ActionPull, auth.ActionPush)
+
+	manifests := make([]ocispec.Descriptor, 0, len(opts.sources))
+	for _, source := range opts.sources {
+		desc, err := fetchPlatformManifest(ctx, target, source)
+		if err != nil {
+			return err
+		}
+		_ = opts.PrintVerbose("Fetched", source, desc.Platform.OS+"/"+desc.Platform.Architecture)
+		manifests = append(manifests, desc)
+	}
+
+	indexBytes, err := json.Marshal(ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: manifests,
+	})
+	if err != nil {
+		return err
+	}
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, indexBytes)
+	if err := opts.PrintStatus(desc, "Uploading"); err != nil {
+		return err
+	}
+	if opts.Reference == "" {
+		desc, err = oras.PushBytes(ctx, target, ocispec.MediaTypeImageIndex, indexBytes)
+	} else {
+		desc, err = oras.TagBytes(ctx, target, ocispec.MediaTypeImageIndex, indexBytes, opts.Reference)
+	}
+	if err != nil {
+		return err
+	}
+	if err := opts.PrintStatus(desc, "Uploaded "); err != nil {
+		return err
+	}
+
+	if opts.OutputDescriptor {
+		descJSON, err := opts.Marshal(desc)
+		if err != nil {
+			return err
+		}
+		return opts.Output(os.Stdout, descJSON)
+	}
+	_ = opts.Println("Pushed", opts.AnnotatedReference())
+	_ = opts.Println("Digest:", desc.Digest)
+	return nil
+}
+
+// fetchPlatformManifest resolves the image manifest of reference and returns
+// its descriptor with the platform read from the image config.
+func fetchPlatformManifest(ctx context.Context, target oras.ReadOnlyTarget, reference string) (ocispec.Descriptor, error) {
+	desc, manifestBytes, err := oras.FetchBytes(ctx, target, reference, oras.DefaultFetchBytesOptions)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to fetch %s: %w", reference, err)
+	}
+	if !descriptor.IsImageManifest(desc) {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: unsupported media type %q, only image manifests can be added to an index", reference, desc.MediaType)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to parse the manifest of %s: %w", reference, err)
+	}
+	configBytes, err := content.FetchAll(ctx, target, manifest.Config)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to fetch the config of %s: %w", reference, err)
+	}
+	var platform ocispec.Platform
+	if err := json.Unmarshal(configBytes, &platform); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to parse the config of %s: %w", reference, err)
+	}
+	if platform.OS == "" || platform.Architecture == "" {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: the platform is not populated in the image config", reference)
+	}
+	return ocispec.Descriptor{
+		MediaType: desc.MediaType,
+		Digest:    desc.Digest,
+		Size:      desc.Size,
+		Platform:  &platform,
+	}, nil
+}
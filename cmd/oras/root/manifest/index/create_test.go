@@ -0,0 +1,85 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package index
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func pushManifest(t *testing.T, store *memory.Store, tag string, configBytes []byte) ocispec.Descriptor {
+	t.Helper()
+	ctx := context.Background()
+	config := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, configBytes)
+	if err := store.Push(ctx, config, bytes.NewReader(configBytes)); err != nil {
+		t.Fatal(err)
+	}
+	manifestBytes, err := json.Marshal(ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    []ocispec.Descriptor{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifestBytes)
+	if err := store.Push(ctx, desc, bytes.NewReader(manifestBytes)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Tag(ctx, desc, tag); err != nil {
+		t.Fatal(err)
+	}
+	return desc
+}
+
+func Test_fetchPlatformManifest(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	arm64 := pushManifest(t, store, "arm64", []byte(`{"architecture":"arm64","os":"linux","variant":"v8"}`))
+	pushManifest(t, store, "noPlatform", []byte(`{}`))
+	config := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, []byte("{}"))
+
+	got, err := fetchPlatformManifest(ctx, store, "arm64")
+	if err != nil {
+		t.Fatal("fetchPlatformManifest() error =", err)
+	}
+	want := ocispec.Descriptor{
+		MediaType: arm64.MediaType,
+		Digest:    arm64.Digest,
+		Size:      arm64.Size,
+		Platform: &ocispec.Platform{
+			Architecture: "arm64",
+			OS:           "linux",
+			Variant:      "v8",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fetchPlatformManifest() = %v, want %v", got, want)
+	}
+
+	for _, ref := range []string{"noPlatform", config.Digest.String(), "missing"} {
+		if _, err := fetchPlatformManifest(ctx, store, ref); err == nil {
+			t.Errorf("fetchPlatformManifest(%q) error = nil, want error", ref)
+		}
+	}
+}
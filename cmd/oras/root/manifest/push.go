@@ -147,6 +147,9 @@ func pushManifest(cmd *cobra.Command, opts pushOptions) error {
 
 	// prepare manifest descriptor
 	desc := content.NewDescriptorFromBytes(mediaType, contentBytes)
+	if err := opts.CheckMetadataSize(desc); err != nil {
+		return err
+	}
 
 	ref := opts.Reference
 	if ref == "" {
@@ -0,0 +1,140 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras/cmd/oras/internal/argument"
+	"oras.land/oras/cmd/oras/internal/command"
+	oerrors "oras.land/oras/cmd/oras/internal/errors"
+	"oras.land/oras/cmd/oras/internal/option"
+	"oras.land/oras/internal/registryutil"
+)
+
+type retagOptions struct {
+	option.Common
+	option.Target
+
+	newTag    string
+	overwrite bool
+}
+
+func retagCmd() *cobra.Command {
+	var opts retagOptions
+	cmd := &cobra.Command{
+		Use:   "retag [flags] <name>@<digest> <new_tag>",
+		Short: "Tag an existing manifest without re-pushing any content",
+		Long: `Tag an existing manifest without re-pushing any content
+
+The command fails if the new tag already points to a different manifest,
+unless --overwrite is applied.
+
+Example - Tag the manifest sha256:9463e0d192846bc994279417b50114606712d516aab45f4d8b31cbc6e46aad71 in 'localhost:5000/hello' with 'v1':
+  oras manifest retag localhost:5000/hello@sha256:9463e0d192846bc994279417b50114606712d516aab45f4d8b31cbc6e46aad71 v1
+
+Example - [Preview] Move the existing tag 'latest' in 'localhost:5000/hello' to the manifest sha256:9463e0d192846bc994279417b50114606712d516aab45f4d8b31cbc6e46aad71:
+  oras manifest retag --overwrite localhost:5000/hello@sha256:9463e0d192846bc994279417b50114606712d516aab45f4d8b31cbc6e46aad71 latest
+
+Example - Tag a manifest in an OCI image layout folder 'layout-dir' with 'v1':
+  oras manifest retag --oci-layout layout-dir@sha256:9463e0d192846bc994279417b50114606712d516aab45f4d8b31cbc6e46aad71 v1
+`,
+		Args: oerrors.CheckArgs(argument.Exactly(2), "the manifest digest and the new tag"),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			opts.RawReference = args[0]
+			opts.newTag = args[1]
+			if err := (registry.Reference{Reference: opts.newTag}).ValidateReferenceAsTag(); err != nil {
+				return fmt.Errorf("invalid tag %q: %w", opts.newTag, err)
+			}
+			return option.Parse(cmd, &opts)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return retagManifest(cmd, &opts)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&opts.overwrite, "overwrite", "", false, "[Preview] move the tag if it already points to a different manifest")
+	option.ApplyFlags(&opts, cmd.Flags())
+	return oerrors.Command(cmd, &opts.Target)
+}
+
+func retagManifest(cmd *cobra.Command, opts *retagOptions) error {
+	ctx, logger := command.GetLogger(cmd, &opts.Common)
+	if _, err := digest.Parse(opts.Reference); err != nil {
+		return &oerrors.Error{
+			Err:            fmt.Errorf("%s: the manifest must be referenced by digest", opts.RawReference),
+			Recommendation: fmt.Sprintf(`Use "oras tag" to tag the manifest referenced by tag, or specify it as %s@<digest>`, opts.Path),
+		}
+	}
+	target, err := opts.NewTarget(opts.Common, logger)
+	if err != nil {
+		return err
+	}
+	ctx = registryutil.WithScopeHint(ctx, target, auth.ActionPull, auth.ActionPush)
+
+	desc, err := target.Resolve(ctx, opts.Reference)
+	if err != nil {
+		if errors.Is(err, errdef.ErrNotFound) {
+			return fmt.Errorf("%s: the specified manifest does not exist", opts.RawReference)
+		}
+		return err
+	}
+	tagged, err := checkRetag(ctx, target, desc, opts.newTag, opts.overwrite)
+	if err != nil {
+		return err
+	}
+	if tagged {
+		_ = opts.Println("Exists", opts.newTag)
+	} else {
+		if err := target.Tag(ctx, desc, opts.newTag); err != nil {
+			return fmt.Errorf("failed to tag %s with %s: %w", opts.RawReference, opts.newTag, err)
+		}
+		_ = opts.Println("Tagged", opts.newTag)
+	}
+	_ = opts.Println("Digest:", desc.Digest)
+	return nil
+}
+
+// checkRetag checks whether tag can be pointed to desc. It returns true if tag
+// already points to desc.
+func checkRetag(ctx context.Context, resolver oras.ReadOnlyTarget, desc ocispec.Descriptor, tag string, overwrite bool) (bool, error) {
+	current, err := resolver.Resolve(ctx, tag)
+	if err != nil {
+		if errors.Is(err, errdef.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if current.Digest == desc.Digest {
+		return true, nil
+	}
+	if !overwrite {
+		return false, &oerrors.Error{
+			Err:            fmt.Errorf("the tag %q already points to %s", tag, current.Digest),
+			Recommendation: "Apply --overwrite to move the tag",
+		}
+	}
+	return false, nil
+}
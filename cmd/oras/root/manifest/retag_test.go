@@ -0,0 +1,67 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func Test_checkRetag(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	var descs []ocispec.Descriptor
+	for _, b := range [][]byte{[]byte(`{"schemaVersion":2}`), []byte(`{"schemaVersion":2,"layers":[]}`)} {
+		desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, b)
+		if err := store.Push(ctx, desc, bytes.NewReader(b)); err != nil {
+			t.Fatal(err)
+		}
+		descs = append(descs, desc)
+	}
+	if err := store.Tag(ctx, descs[0], "v1"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		desc       ocispec.Descriptor
+		tag        string
+		overwrite  bool
+		wantTagged bool
+		wantErr    bool
+	}{
+		{name: "new tag", desc: descs[1], tag: "v2"},
+		{name: "already tagged", desc: descs[0], tag: "v1", wantTagged: true},
+		{name: "tag points to another manifest", desc: descs[1], tag: "v1", wantErr: true},
+		{name: "overwrite", desc: descs[1], tag: "v1", overwrite: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checkRetag(ctx, store, tt.desc, tt.tag, tt.overwrite)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkRetag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.wantTagged {
+				t.Errorf("checkRetag() = %v, want %v", got, tt.wantTagged)
+			}
+		})
+	}
+}
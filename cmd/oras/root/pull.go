@@ -20,8 +20,17 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/cobra"
 	"oras.land/oras-go/v2"
@@ -35,10 +44,24 @@ import (
 	oerrors "oras.land/oras/cmd/oras/internal/errors"
 	"oras.land/oras/cmd/oras/internal/fileref"
 	"oras.land/oras/cmd/oras/internal/option"
+	"oras.land/oras/cmd/oras/internal/output"
+	"oras.land/oras/internal/cache"
+	"oras.land/oras/internal/contentutil"
 	"oras.land/oras/internal/descriptor"
 	"oras.land/oras/internal/graph"
 )
 
+// errUnsupportedMediaType is returned when the pulled artifact references
+// content of a media type not allowed by --allowed-media-type.
+var errUnsupportedMediaType = errors.New("unsupported media type")
+
+const (
+	// orderManifest downloads the layers in the order of the manifest.
+	orderManifest = "manifest"
+	// orderSmallestFirst downloads the smallest layers first.
+	orderSmallestFirst = "smallest-first"
+)
+
 type pullOptions struct {
 	option.Cache
 	option.Common
@@ -46,12 +69,311 @@ type pullOptions struct {
 	option.Target
 	option.Format
 
-	concurrency       int
-	KeepOldFiles      bool
-	IncludeSubject    bool
-	PathTraversal     bool
-	Output            string
-	ManifestConfigRef string
+	concurrency        int
+	includedMediaTypes []string
+	allowedMediaTypes  []string
+	includedNames      []string
+	excludedNames      []string
+	verifyOnly         bool
+	statusEvents       bool
+	maxDownloadSize    int64
+	toStdout           bool
+	manifestExportPath string
+	stripPrefix        string
+	tarPath            string
+	decompress         bool
+	fileHook           string
+	downloadOrder      string
+	preserveAttributes bool
+	preserveSymlinks   bool
+	KeepOldFiles       bool
+	IncludeSubject     bool
+	PathTraversal      bool
+	Output             string
+	ManifestConfigRef  string
+}
+
+// checkMediaTypes returns errUnsupportedMediaType listing the config and
+// layers of a manifest whose media types are not allowed.
+func (po *pullOptions) checkMediaTypes(manifest ocispec.Descriptor, config *ocispec.Descriptor, nodes []ocispec.Descriptor) error {
+	if len(po.allowedMediaTypes) == 0 {
+		return nil
+	}
+	if config != nil {
+		nodes = append([]ocispec.Descriptor{*config}, nodes...)
+	}
+	var offenders []string
+	for _, node := range nodes {
+		if descriptor.IsManifest(node) || content.Equal(node, ocispec.DescriptorEmptyJSON) || slices.Contains(po.allowedMediaTypes, node.MediaType) {
+			continue
+		}
+		offender := node.Digest.String()
+		if name := node.Annotations[ocispec.AnnotationTitle]; name != "" {
+			offender = name
+		}
+		offenders = append(offenders, fmt.Sprintf("%s (%s)", offender, node.MediaType))
+	}
+	if len(offenders) == 0 {
+		return nil
+	}
+	return &oerrors.Error{
+		Err:            fmt.Errorf("%w: %s references %s", errUnsupportedMediaType, manifest.Digest, strings.Join(offenders, ", ")),
+		Recommendation: "Allow the media types via --allowed-media-type if the content is trusted",
+	}
+}
+
+// layerFilter returns the predicate deciding which layers are pulled, or nil
+// if every layer is pulled.
+func (po *pullOptions) layerFilter() func(desc ocispec.Descriptor) bool {
+	if len(po.includedMediaTypes) == 0 && len(po.includedNames) == 0 && len(po.excludedNames) == 0 {
+		return nil
+	}
+	return func(desc ocispec.Descriptor) bool {
+		if len(po.includedMediaTypes) != 0 && !slices.Contains(po.includedMediaTypes, desc.MediaType) {
+			return false
+		}
+		name := desc.Annotations[ocispec.AnnotationTitle]
+		if len(po.includedNames) != 0 && !matchAny(po.includedNames, name) {
+			return false
+		}
+		return !matchAny(po.excludedNames, name)
+	}
+}
+
+// checkDownload walks the graph rooted at root before downloading anything, so
+// that the content of all the manifests of an index is checked upfront. It
+// returns an error if a manifest references a media type not allowed by
+// --allowed-media-type, or if the named files to pull exceed
+// --max-download-size in total, counting each blob once.
+func (po *pullOptions) checkDownload(ctx context.Context, fetcher content.Fetcher, root ocispec.Descriptor, keep func(desc ocispec.Descriptor) bool, configPath, configMediaType string) error {
+	var size int64
+	visited := make(map[digest.Digest]bool)
+	var walk func(desc ocispec.Descriptor) error
+	walk = func(desc ocispec.Descriptor) error {
+		if visited[desc.Digest] {
+			return nil
+		}
+		visited[desc.Digest] = true
+		if !descriptor.IsManifest(desc) {
+			if desc.Annotations[ocispec.AnnotationTitle] != "" && (keep == nil || keep(desc)) {
+				size += desc.Size
+			}
+			return nil
+		}
+		nodes, subject, config, err := graph.Successors(ctx, fetcher, desc)
+		if err != nil {
+			return err
+		}
+		if err := po.checkMediaTypes(desc, config, nodes); err != nil {
+			return err
+		}
+		if subject != nil && po.IncludeSubject {
+			nodes = append(nodes, *subject)
+		}
+		if config != nil && configPath != "" && (configMediaType == "" || config.MediaType == configMediaType) && !visited[config.Digest] {
+			// the config is pulled as the file at configPath
+			visited[config.Digest] = true
+			size += config.Size
+		}
+		for _, node := range nodes {
+			if err := walk(node); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(root); err != nil {
+		return err
+	}
+	if po.maxDownloadSize > 0 && size > po.maxDownloadSize {
+		return &oerrors.Error{
+			Err:            fmt.Errorf("the layers of %s are %d bytes in total, exceeding the limit of %d bytes", po.RawReference, size, po.maxDownloadSize),
+			Recommendation: "Pull a subset of the files via --include-media-type or --include-name, or raise --max-download-size",
+		}
+	}
+	return nil
+}
+
+// matchAny reports whether name matches any of the patterns. The patterns are
+// validated when parsing the flags.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// translateName returns the name the file named name is pulled as.
+func (po *pullOptions) translateName(name string) string {
+	if po.stripPrefix == "" {
+		return name
+	}
+	prefix := strings.TrimSuffix(po.stripPrefix, "/") + "/"
+	if rest, ok := strings.CutPrefix(name, prefix); ok && rest != "" {
+		return rest
+	}
+	return name
+}
+
+// restorePulledAttributes restores the attributes recorded by push on the
+// files of layers pulled into outputDir, after the chunks are joined and, if
+// decompressed, the gzip layers are decompressed. The layers left out by the
+// pull filters must not be passed as their files are not written.
+func restorePulledAttributes(outputDir string, layers []ocispec.Descriptor, decompressed bool) error {
+	for _, layer := range layers {
+		if _, ok := layer.Annotations[annotationFileMode]; !ok {
+			continue
+		}
+		if _, ok := layer.Annotations[annotationSymlink]; ok {
+			continue
+		}
+		name := layer.Annotations[ocispec.AnnotationTitle]
+		if newName, ok := decompressedName(layer); ok && decompressed {
+			name = newName
+		}
+		if name == "" {
+			continue
+		}
+		path := name
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(outputDir, name)
+		}
+		if err := restoreFileAttributes(path, layer.Annotations); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keptSuccessors returns the successors of desc, leaving out the layers
+// dropped by keep so that only the files written by the pull are processed.
+func keptSuccessors(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor, keep func(desc ocispec.Descriptor) bool) ([]ocispec.Descriptor, error) {
+	if keep == nil {
+		return content.Successors(ctx, fetcher, desc)
+	}
+	nodes, subject, config, err := graph.Successors(ctx, fetcher, desc)
+	if err != nil {
+		return nil, err
+	}
+	var successors []ocispec.Descriptor
+	if subject != nil {
+		successors = append(successors, *subject)
+	}
+	if config != nil {
+		successors = append(successors, *config)
+	}
+	for _, node := range nodes {
+		if descriptor.IsManifest(node) || keep(node) {
+			successors = append(successors, node)
+		}
+	}
+	return successors, nil
+}
+
+// runFileHook runs hook for the file name pulled into outputDir from the
+// layer desc, before the file is joined, decompressed or renamed.
+func runFileHook(ctx context.Context, hook, outputDir, name string, desc ocispec.Descriptor) error {
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(outputDir, name)
+	}
+	cmd := exec.CommandContext(ctx, hook)
+	cmd.Env = append(os.Environ(),
+		"ORAS_FILE_NAME="+name,
+		"ORAS_FILE_PATH="+path,
+		"ORAS_FILE_DIGEST="+desc.Digest.String(),
+		"ORAS_FILE_MEDIA_TYPE="+desc.MediaType,
+	)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return &oerrors.Error{
+			Err:            fmt.Errorf("pull aborted by the file hook on %s: %w", name, err),
+			Recommendation: fmt.Sprintf("Check the output of %s for why the file is rejected", hook),
+		}
+	}
+	return nil
+}
+
+// writeTar archives the files pulled into the output directory to the tar
+// path.
+func (po *pullOptions) writeTar(stdout io.Writer) error {
+	if po.tarPath == "-" {
+		return writeTar(stdout, po.Output)
+	}
+	fp, err := os.Create(po.tarPath)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	if err := writeTar(fp, po.Output); err != nil {
+		return err
+	}
+	return fp.Close()
+}
+
+// renamePulledFiles moves the files pulled into outputDir to their translated
+// names. Names of files which no longer exist, such as joined chunks, are
+// skipped. Pulled files translated to the same name are rejected as
+// duplicates.
+func renamePulledFiles(outputDir string, names []string, po *pullOptions) error {
+	// pulledAs maps the translated names to the pulled names, checked for
+	// duplicates before any file is moved
+	pulledAs := make(map[string]string)
+	var renamed []string
+	for _, name := range names {
+		newName := po.translateName(name)
+		if pulledAs[newName] == name {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(outputDir, name)); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if other, ok := pulledAs[newName]; ok {
+			return fmt.Errorf("%s: %w: both %s and %s are pulled as it", newName, file.ErrDuplicateName, other, name)
+		}
+		pulledAs[newName] = name
+		if newName == name {
+			continue
+		}
+		if !po.PathTraversal && !filepath.IsLocal(newName) {
+			return fmt.Errorf("%s: %w", newName, file.ErrPathTraversalDisallowed)
+		}
+		renamed = append(renamed, name)
+	}
+	for _, name := range renamed {
+		newName := po.translateName(name)
+		oldPath := filepath.Join(outputDir, name)
+		newPath := filepath.Join(outputDir, newName)
+		if _, err := os.Lstat(newPath); err == nil {
+			if po.KeepOldFiles {
+				return fmt.Errorf("%s: %w", newName, file.ErrDuplicateName)
+			}
+			if err := os.RemoveAll(newPath); err != nil {
+				return err
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(newPath), 0777); err != nil {
+			return err
+		}
+		if err := os.Rename(oldPath, newPath); err != nil {
+			return err
+		}
+	}
+	// remove the directories emptied by the renaming, which fails otherwise
+	prefix := filepath.FromSlash(strings.TrimSuffix(po.stripPrefix, "/"))
+	if !filepath.IsLocal(prefix) {
+		return nil
+	}
+	prefixDir := filepath.Join(outputDir, prefix)
+	for dir := prefixDir; dir != filepath.Clean(outputDir) && dir != "."; dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
 }
 
 func pullCmd() *cobra.Command {
@@ -77,12 +399,68 @@ Example - Pull files from a registry with local cache:
   export ORAS_CACHE=~/.oras/cache
   oras pull localhost:5000/hello:v1
 
+Example - Pull files from a registry with local cache limited to 1 GiB, evicting the least recently used blobs:
+  export ORAS_CACHE=~/.oras/cache
+  export ORAS_CACHE_MAX_BYTES=1073741824
+  oras pull localhost:5000/hello:v1
+
+Example - [Preview] Pull files and export the pulled manifest to a specified path:
+  oras pull --export-manifest manifest.json localhost:5000/hello:v1
+
+Example - [Preview] Pull files named under "build/" into the current directory without the "build/" prefix:
+  oras pull --strip-prefix build localhost:5000/hello:v1
+
+Example - [Preview] Pull files and decompress the gzip layers pushed with --compress:
+  oras pull --decompress localhost:5000/hello:v1
+
+Example - [Preview] Pull files and scan each of them with "./scan.sh", aborting the pull if the scan fails:
+  oras pull --file-hook ./scan.sh localhost:5000/hello:v1
+
+Example - [Preview] Pull files with the mode and the modification time recorded by push --preserve-attributes:
+  oras pull --preserve-attributes localhost:5000/hello:v1
+
+Example - [Preview] Pull files with the symbolic links recorded by push --preserve-symlinks:
+  oras pull --preserve-symlinks localhost:5000/hello:v1
+
+Example - [Preview] Pull files into the tar archive "files.tar" instead of a directory:
+  oras pull --tar files.tar localhost:5000/hello:v1
+
+Example - [Preview] Pull files as a tar archive written to stdout:
+  oras pull --tar - localhost:5000/hello:v1 | tar -x -C out
+
 Example - Pull files from a registry with certain platform:
   oras pull --platform linux/arm/v5 localhost:5000/hello:v1
 
+Example - [Preview] Pull files, starting with the smallest ones:
+  oras pull --download-order smallest-first localhost:5000/hello:v1
+
 Example - Pull all files with concurrency level tuned:
   oras pull --concurrency 6 localhost:5000/hello:v1
 
+Example - [Preview] Pull files, cancelling and retrying the download of any blob taking more than 10 minutes:
+  oras pull --blob-timeout 10m localhost:5000/hello:v1
+
+Example - [Preview] Pull files only if all the layers are of media type "application/spdx+json" or "text/plain":
+  oras pull --allowed-media-type application/spdx+json --allowed-media-type text/plain localhost:5000/hello:v1
+
+Example - [Preview] Pull only the files of media type "application/spdx+json":
+  oras pull --include-media-type application/spdx+json localhost:5000/hello:v1
+
+Example - [Preview] Pull only the YAML files under "configs", excluding the signature files:
+  oras pull --include-name 'configs/*.yaml' --exclude-name '*.sig' localhost:5000/hello:v1
+
+Example - [Preview] Pull files and print the download status events as JSON lines to stderr:
+  oras pull --status-events localhost:5000/hello:v1
+
+Example - [Preview] Pull files only if the layers to download are no larger than 1 GB in total:
+  oras pull --max-download-size 1000000000 localhost:5000/hello:v1
+
+Example - [Preview] Write the content of the single file of media type "application/json" to stdout:
+  oras pull --stdout --include-media-type application/json localhost:5000/hello:v1
+
+Example - Verify the integrity of an artifact without writing any file:
+  oras pull --verify-only localhost:5000/hello:v1
+
 Example - Pull artifact files from an OCI image layout folder 'layout-dir':
   oras pull --oci-layout layout-dir:v1
 
@@ -92,6 +470,32 @@ Example - Pull artifact files from an OCI layout archive 'layout.tar':
 		Args: oerrors.CheckArgs(argument.Exactly(1), "the artifact reference you want to pull"),
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			opts.RawReference = args[0]
+			if opts.verifyOnly {
+				if err := oerrors.CheckMutuallyExclusiveFlags(cmd.Flags(), "verify-only", "output", "config", "decompress", "file-hook", "preserve-attributes", "preserve-symlinks", "keep-old-files", "allow-path-traversal", "include-subject", "include-media-type", "include-name", "exclude-name", "export-manifest", "format"); err != nil {
+					return err
+				}
+			}
+			if opts.tarPath != "" {
+				if err := oerrors.CheckMutuallyExclusiveFlags(cmd.Flags(), "tar", "output", "keep-old-files", "verify-only", "stdout"); err != nil {
+					return err
+				}
+			}
+			if opts.toStdout {
+				if err := oerrors.CheckMutuallyExclusiveFlags(cmd.Flags(), "stdout", "output", "config", "decompress", "file-hook", "preserve-attributes", "preserve-symlinks", "keep-old-files", "allow-path-traversal", "include-subject", "verify-only", "status-events", "export-manifest", "format"); err != nil {
+					return err
+				}
+			}
+			if opts.downloadOrder != orderManifest && opts.downloadOrder != orderSmallestFirst {
+				return fmt.Errorf("unsupported download order %q for --download-order, options: %s, %s", opts.downloadOrder, orderManifest, orderSmallestFirst)
+			}
+			if opts.maxDownloadSize < 0 {
+				return fmt.Errorf("invalid value %d for --max-download-size: must not be negative", opts.maxDownloadSize)
+			}
+			for _, pattern := range slices.Concat(opts.includedNames, opts.excludedNames) {
+				if _, err := path.Match(pattern, ""); err != nil {
+					return fmt.Errorf("invalid file name pattern %q: %w", pattern, err)
+				}
+			}
 			return option.Parse(cmd, &opts)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -104,21 +508,48 @@ Example - Pull artifact files from an OCI layout archive 'layout.tar':
 	cmd.Flags().BoolVarP(&opts.IncludeSubject, "include-subject", "", false, "[Preview] recursively pull the subject of artifacts")
 	cmd.Flags().StringVarP(&opts.Output, "output", "o", ".", "output directory")
 	cmd.Flags().StringVarP(&opts.ManifestConfigRef, "config", "", "", "output manifest config file")
+	cmd.Flags().StringVarP(&opts.tarPath, "tar", "", "", "[Preview] write the pulled files to a tar archive at `path` instead of the output directory, use - for stdout")
+	cmd.Flags().BoolVarP(&opts.decompress, "decompress", "", false, "[Preview] decompress the pulled files of gzip layers, named without the .gz extension")
+	cmd.Flags().StringVarP(&opts.fileHook, "file-hook", "", "", "[Preview] `path` of an executable run for each pulled file, described by the ORAS_FILE_* environment variables, a non-zero exit status aborts the pull")
+	cmd.Flags().BoolVarP(&opts.preserveAttributes, "preserve-attributes", "", false, "[Preview] restore the mode, the modification time and, if running as root, the ownership of the files pushed with --preserve-attributes")
+	cmd.Flags().BoolVarP(&opts.preserveSymlinks, "preserve-symlinks", "", false, "[Preview] restore the symbolic links pushed with --preserve-symlinks, links out of the output directory are rejected unless --allow-path-traversal is set")
+	cmd.Flags().StringVarP(&opts.stripPrefix, "strip-prefix", "", "", "[Preview] remove the directory `prefix` from the names of the pulled files")
+	cmd.Flags().StringVarP(&opts.manifestExportPath, "export-manifest", "", "", "[Preview] `path` of the pulled manifest")
 	cmd.Flags().IntVarP(&opts.concurrency, "concurrency", "", 3, "concurrency level")
+	cmd.Flags().StringArrayVarP(&opts.includedMediaTypes, "include-media-type", "", nil, "[Preview] only pull the layers of the `media type`, the other layers are skipped")
+	cmd.Flags().StringArrayVarP(&opts.allowedMediaTypes, "allowed-media-type", "", nil, "[Preview] fail before downloading any blob if the config or a layer is not of an allowed `media type`")
+	cmd.Flags().StringArrayVarP(&opts.includedNames, "include-name", "", nil, "[Preview] only pull the files whose name matches the glob `pattern`, the other files are skipped")
+	cmd.Flags().StringArrayVarP(&opts.excludedNames, "exclude-name", "", nil, "[Preview] skip the files whose name matches the glob `pattern`")
+	cmd.Flags().BoolVarP(&opts.toStdout, "stdout", "", false, "[Preview] write the content of the single selected layer to stdout instead of pulling files, layers can be selected via --include-media-type and --include-name")
+	cmd.Flags().StringVarP(&opts.downloadOrder, "download-order", "", orderManifest, "[Preview] order in which the layers start downloading, options: manifest, smallest-first")
+	cmd.Flags().Int64VarP(&opts.maxDownloadSize, "max-download-size", "", 0, "[Preview] abort before downloading if the declared sizes of the layers to pull exceed `bytes` in total, unlimited if 0")
+	cmd.Flags().BoolVarP(&opts.statusEvents, "status-events", "", false, "[Preview] print the status of each downloaded node as JSON lines to stderr instead of the status output")
+	cmd.Flags().BoolVarP(&opts.verifyOnly, "verify-only", "", false, "[Preview] fetch and verify all the content of the artifact without writing files")
 	opts.SetTypes(option.FormatTypeText, option.FormatTypeJSON, option.FormatTypeGoTemplate)
+	opts.EnableRetryFlags()
 	option.ApplyFlags(&opts, cmd.Flags())
 	return oerrors.Command(cmd, &opts.Target)
 }
 
 func runPull(cmd *cobra.Command, opts *pullOptions) error {
 	ctx, logger := command.GetLogger(cmd, &opts.Common)
+	if opts.tarPath == "-" {
+		// keep stdout clean for the tar stream
+		opts.Printer = output.NewPrinter(cmd.ErrOrStderr(), cmd.ErrOrStderr(), opts.Verbose)
+	}
 	statusHandler, metadataHandler, err := display.NewPullHandler(opts.Printer, opts.Format, opts.Path, opts.TTY)
 	if err != nil {
 		return err
 	}
+	if opts.statusEvents {
+		statusHandler = status.NewEventPullHandler(cmd.ErrOrStderr())
+	}
 	// Copy Options
 	copyOptions := oras.DefaultCopyOptions
 	copyOptions.Concurrency = opts.concurrency
+	if opts.MaxMetadataBytes > 0 {
+		copyOptions.MaxMetadataBytes = opts.MaxMetadataBytes
+	}
 	if opts.Platform.Platform != nil {
 		copyOptions.WithTargetPlatform(opts.Platform.Platform)
 	}
@@ -129,10 +560,36 @@ func runPull(cmd *cobra.Command, opts *pullOptions) error {
 	if err := opts.EnsureReferenceNotEmpty(cmd, true); err != nil {
 		return err
 	}
-	src, err := opts.CachedTarget(target)
+	var src oras.ReadOnlyTarget = target
+	if opts.BlobTimeout > 0 {
+		src = contentutil.NewTimeoutReadOnlyTarget(target, opts.BlobTimeout, opts.MaxRetries())
+	}
+	if opts.verifyOnly {
+		// content is always fetched from the target instead of the cache
+		desc, err := doVerify(ctx, src, copyOptions, statusHandler, opts)
+		if err != nil {
+			return err
+		}
+		_ = opts.Println("Verified", opts.AnnotatedReference())
+		return opts.Println("Digest:", desc.Digest)
+	}
+	src, err = opts.CachedTarget(src)
 	if err != nil {
 		return err
 	}
+	if opts.toStdout {
+		_, err := doPullToStdout(ctx, src, cmd.OutOrStdout(), opts)
+		return err
+	}
+	if opts.tarPath != "" {
+		// pull into a temporary directory archived at the end
+		tempDir, err := os.MkdirTemp("", "oras_pull_*")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tempDir)
+		opts.Output = tempDir
+	}
 	dst, err := file.New(opts.Output)
 	if err != nil {
 		return err
@@ -148,6 +605,21 @@ func runPull(cmd *cobra.Command, opts *pullOptions) error {
 		}
 		return err
 	}
+	if opts.tarPath != "" {
+		if err := opts.writeTar(cmd.OutOrStdout()); err != nil {
+			return err
+		}
+	}
+	if opts.manifestExportPath != "" {
+		// the pulled manifest is kept in memory by the file store
+		manifestBytes, err := content.FetchAll(ctx, dst, desc)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(opts.manifestExportPath, manifestBytes, 0666); err != nil {
+			return err
+		}
+	}
 
 	return metadataHandler.OnCompleted(&opts.Target, desc)
 }
@@ -171,6 +643,9 @@ func doPull(ctx context.Context, src oras.ReadOnlyTarget, dst oras.GraphTarget,
 	}()
 	var printed sync.Map
 	var getConfigOnce sync.Once
+	var pulledNames []string
+	var pulledNamesLock sync.Mutex
+	keep := po.layerFilter()
 	opts.FindSuccessors = func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
 		statusFetcher := content.FetcherFunc(func(ctx context.Context, target ocispec.Descriptor) (fetched io.ReadCloser, fetchErr error) {
 			if _, ok := printed.LoadOrStore(descriptor.GenerateContentKey(target), true); ok {
@@ -195,6 +670,17 @@ func doPull(ctx context.Context, src oras.ReadOnlyTarget, dst oras.GraphTarget,
 		if err != nil {
 			return nil, err
 		}
+		if keep != nil {
+			var kept []ocispec.Descriptor
+			for _, node := range nodes {
+				if descriptor.IsManifest(node) || keep(node) {
+					kept = append(kept, node)
+				} else if err := notifyOnce(&printed, node, statusHandler.OnNodeSkipped); err != nil {
+					return nil, err
+				}
+			}
+			nodes = kept
+		}
 		if subject != nil && po.IncludeSubject {
 			nodes = append(nodes, *subject)
 		}
@@ -239,21 +725,36 @@ func doPull(ctx context.Context, src oras.ReadOnlyTarget, dst oras.GraphTarget,
 			}
 			ret = append(ret, s)
 		}
+		if po.downloadOrder == orderSmallestFirst {
+			sort.SliceStable(ret, func(i, j int) bool {
+				return ret[i].Size < ret[j].Size
+			})
+		}
 		return ret, nil
 	}
 
 	opts.PreCopy = func(ctx context.Context, desc ocispec.Descriptor) error {
 		return notifyOnce(&printed, desc, statusHandler.OnNodeDownloading)
 	}
+	var hooked sync.Map
 	opts.PostCopy = func(ctx context.Context, desc ocispec.Descriptor) error {
 		// restore named but deduplicated successor nodes
-		successors, err := content.Successors(ctx, dst, desc)
+		successors, err := keptSuccessors(ctx, dst, desc, keep)
 		if err != nil {
 			return err
 		}
 		for _, s := range successors {
 			if name, ok := s.Annotations[ocispec.AnnotationTitle]; ok {
-				if err = metadataHandler.OnFilePulled(name, po.Output, s, po.Path); err != nil {
+				pulledNamesLock.Lock()
+				pulledNames = append(pulledNames, name)
+				if chunkOf, ok := s.Annotations[annotationChunkOf]; ok {
+					pulledNames = append(pulledNames, chunkOf)
+				}
+				if newName, ok := decompressedName(s); ok && po.decompress {
+					pulledNames = append(pulledNames, newName)
+				}
+				pulledNamesLock.Unlock()
+				if err = metadataHandler.OnFilePulled(po.translateName(name), po.Output, s, po.Path, cache.Hit(src, s)); err != nil {
 					return err
 				}
 				if err = notifyOnce(&printed, s, statusHandler.OnNodeRestored); err != nil {
@@ -261,13 +762,133 @@ func doPull(ctx context.Context, src oras.ReadOnlyTarget, dst oras.GraphTarget,
 				}
 			}
 		}
+		if po.fileHook != "" {
+			for _, s := range successors {
+				name, ok := s.Annotations[ocispec.AnnotationTitle]
+				if !ok {
+					continue
+				}
+				if _, ok := hooked.LoadOrStore(name, true); ok {
+					continue
+				}
+				if err := runFileHook(ctx, po.fileHook, po.Output, name, s); err != nil {
+					return err
+				}
+			}
+		}
+		layers, err := joinChunks(po.Output, successors, po.KeepOldFiles)
+		if err != nil {
+			return err
+		}
+		if po.decompress {
+			if err := decompressLayers(po.Output, layers, po.KeepOldFiles); err != nil {
+				return err
+			}
+		}
+		if po.preserveSymlinks {
+			if err := restoreSymlinks(po.Output, layers, po.PathTraversal); err != nil {
+				return err
+			}
+		}
+		if po.preserveAttributes {
+			if err := restorePulledAttributes(po.Output, layers, po.decompress); err != nil {
+				return err
+			}
+		}
 		printed.Store(descriptor.GenerateContentKey(desc), true)
 		return statusHandler.OnNodeDownloaded(desc)
 	}
 
+	if po.maxDownloadSize > 0 || len(po.allowedMediaTypes) != 0 {
+		resolveOpts := oras.DefaultResolveOptions
+		resolveOpts.TargetPlatform = po.Platform.Platform
+		root, err := oras.Resolve(ctx, src, po.Reference, resolveOpts)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		if err := po.checkDownload(ctx, src, root, keep, configPath, configMediaType); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+
 	// Copy
 	desc, err := oras.Copy(ctx, src, po.Reference, dst, po.Reference, opts)
-	return desc, err
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if po.stripPrefix != "" {
+		if err := renamePulledFiles(po.Output, pulledNames, po); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+	return desc, nil
+}
+
+// doPullToStdout writes the content of the single layer of the artifact kept
+// by the layer filter to w, verifying it against its descriptor.
+func doPullToStdout(ctx context.Context, src oras.ReadOnlyTarget, w io.Writer, po *pullOptions) (ocispec.Descriptor, error) {
+	resolveOpts := oras.DefaultResolveOptions
+	resolveOpts.TargetPlatform = po.Platform.Platform
+	root, err := oras.Resolve(ctx, src, po.Reference, resolveOpts)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to resolve %s: %w", po.Reference, err)
+	}
+	nodes, _, config, err := graph.Successors(ctx, src, root)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := po.checkMediaTypes(root, config, nodes); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	keep := po.layerFilter()
+	var layers []ocispec.Descriptor
+	for _, node := range nodes {
+		if descriptor.IsManifest(node) || content.Equal(node, ocispec.DescriptorEmptyJSON) {
+			continue
+		}
+		if keep == nil || keep(node) {
+			layers = append(layers, node)
+		}
+	}
+	if len(layers) != 1 {
+		return ocispec.Descriptor{}, &oerrors.Error{
+			Err:            fmt.Errorf("%d layers of %s are selected, expecting exactly one", len(layers), po.RawReference),
+			Recommendation: "Select a single layer via --include-media-type or --include-name, or a single platform via --platform",
+		}
+	}
+	layer := layers[0]
+	if po.maxDownloadSize > 0 && layer.Size > po.maxDownloadSize {
+		return ocispec.Descriptor{}, fmt.Errorf("the layer %s is %d bytes, exceeding the limit of %d bytes", layer.Digest, layer.Size, po.maxDownloadSize)
+	}
+	rc, err := src.Fetch(ctx, layer)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	defer rc.Close()
+	vr := content.NewVerifyReader(rc, layer)
+	if _, err := io.Copy(w, vr); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return layer, vr.Verify()
+}
+
+// doVerify fetches all the content of the artifact and verifies it against
+// the descriptors. Nothing is written to the disk.
+func doVerify(ctx context.Context, src oras.ReadOnlyTarget, opts oras.CopyOptions, statusHandler status.PullHandler, po *pullOptions) (ocispec.Descriptor, error) {
+	dst, stopTrack, err := statusHandler.TrackTarget(contentutil.NewVerifiedDiscardTarget())
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	defer func() {
+		_ = stopTrack()
+	}()
+	opts.PreCopy = func(ctx context.Context, desc ocispec.Descriptor) error {
+		return statusHandler.OnNodeDownloading(desc)
+	}
+	opts.PostCopy = func(ctx context.Context, desc ocispec.Descriptor) error {
+		return statusHandler.OnNodeDownloaded(desc)
+	}
+	return oras.Copy(ctx, src, po.Reference, dst, po.Reference, opts)
 }
 
 func notifyOnce(notified *sync.Map, s ocispec.Descriptor, notify func(ocispec.Descriptor) error) error {
@@ -16,12 +16,35 @@ limitations under the License.
 package root
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/cobra"
-	"oras.land/oras/cmd/oras/internal/errors"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/content/oci"
+	metadatajson "oras.land/oras/cmd/oras/internal/display/metadata/json"
+	"oras.land/oras/cmd/oras/internal/display/metadata/text"
+	"oras.land/oras/cmd/oras/internal/display/status"
+	oerrors "oras.land/oras/cmd/oras/internal/errors"
 	"oras.land/oras/cmd/oras/internal/option"
+	"oras.land/oras/cmd/oras/internal/output"
 )
 
 func Test_runPull_errType(t *testing.T) {
@@ -36,8 +59,591 @@ func Test_runPull_errType(t *testing.T) {
 		},
 	}
 	got := runPull(cmd, opts).Error()
-	want := errors.UnsupportedFormatTypeError(opts.Format.Type).Error()
+	want := oerrors.UnsupportedFormatTypeError(opts.Format.Type).Error()
 	if got != want {
 		t.Fatalf("got %v, want %v", got, want)
 	}
 }
+
+// newPullTestSource returns a memory store holding an artifact tagged "v1"
+// with the files "hi.txt" and "sbom.json", both recorded with the mode 0640.
+func newPullTestSource(t *testing.T) *memory.Store {
+	t.Helper()
+	ctx := context.Background()
+	src := memory.New()
+	var layers []ocispec.Descriptor
+	for _, file := range []struct{ name, mediaType string }{
+		{"hi.txt", "text/plain"},
+		{"sbom.json", "application/spdx+json"},
+	} {
+		blob := []byte(file.name)
+		desc := content.NewDescriptorFromBytes(file.mediaType, blob)
+		desc.Annotations = map[string]string{
+			ocispec.AnnotationTitle: file.name,
+			annotationFileMode:      "0640",
+		}
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		layers = append(layers, desc)
+	}
+	root, err := oras.PackManifest(ctx, src, oras.PackManifestVersion1_1, "application/vnd.test", oras.PackManifestOptions{Layers: layers})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Tag(ctx, root, "v1"); err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+func Test_runPull_exportManifest(t *testing.T) {
+	ctx := context.Background()
+	layoutDir := t.TempDir()
+	layout, err := oci.New(layoutDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := oras.Copy(ctx, newPullTestSource(t), "v1", layout, "v1", oras.DefaultCopyOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := content.FetchAll(ctx, layout, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exportPath := filepath.Join(t.TempDir(), "manifest.json")
+	cmd := pullCmd()
+	cmd.SetArgs([]string{"--oci-layout", layoutDir + ":v1", "-o", t.TempDir(), "--export-manifest", exportPath})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatal("pull error =", err)
+	}
+	got, err := os.ReadFile(exportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("exported manifest = %s, want %s", got, want)
+	}
+}
+
+func Test_doPull_layerFilter(t *testing.T) {
+	ctx := context.Background()
+	src := newPullTestSource(t)
+
+	outputDir := t.TempDir()
+	dst, err := file.New(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+	var out bytes.Buffer
+	printer := output.NewPrinter(&out, io.Discard, false)
+	opts := &pullOptions{includedMediaTypes: []string{"application/spdx+json"}, Output: outputDir}
+	opts.Reference = "v1"
+	if _, err := doPull(ctx, src, dst, oras.DefaultCopyOptions, text.NewPullHandler(printer), status.NewTextPullHandler(printer), opts); err != nil {
+		t.Fatal("doPull() error =", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "sbom.json")); err != nil {
+		t.Error("included file is not pulled:", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "hi.txt")); !os.IsNotExist(err) {
+		t.Errorf("excluded file is pulled, stat error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Skipped") {
+		t.Errorf("excluded file is not reported as skipped, output = %q", out.String())
+	}
+}
+
+func Test_doPull_layerFilter_preserveAttributes(t *testing.T) {
+	ctx := context.Background()
+	src := newPullTestSource(t)
+
+	tests := []struct {
+		name string
+		opts *pullOptions
+	}{
+		{name: "include name", opts: &pullOptions{includedNames: []string{"hi.txt"}}},
+		{name: "exclude name", opts: &pullOptions{excludedNames: []string{"*.json"}}},
+		{name: "include media type", opts: &pullOptions{includedMediaTypes: []string{"text/plain"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			dst, err := file.New(outputDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer dst.Close()
+			var out bytes.Buffer
+			printer := output.NewPrinter(io.Discard, io.Discard, false)
+			metadataHandler := metadatajson.NewPullHandler(&out, "test")
+			opts := tt.opts
+			opts.preserveAttributes = true
+			opts.Output = outputDir
+			opts.Reference = "v1"
+			desc, err := doPull(ctx, src, dst, oras.DefaultCopyOptions, metadataHandler, status.NewTextPullHandler(printer), opts)
+			if err != nil {
+				t.Fatal("doPull() error =", err)
+			}
+			info, err := os.Stat(filepath.Join(outputDir, "hi.txt"))
+			if err != nil {
+				t.Fatal("kept file is not pulled:", err)
+			}
+			if info.Mode().Perm() != 0640 {
+				t.Errorf("restored mode = %o, want %o", info.Mode().Perm(), 0640)
+			}
+			if err := metadataHandler.OnCompleted(&opts.Target, desc); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out.String(), "hi.txt") || strings.Contains(out.String(), "sbom.json") {
+				t.Errorf("pulled files = %s, want only hi.txt", out.String())
+			}
+		})
+	}
+}
+
+func Test_doPull_layerFilter_fileHook(t *testing.T) {
+	ctx := context.Background()
+	src := newPullTestSource(t)
+
+	hookDir := t.TempDir()
+	logPath := filepath.Join(hookDir, "hooked.log")
+	hook := filepath.Join(hookDir, "hook.sh")
+	script := "#!/bin/sh\ntest -f \"$ORAS_FILE_PATH\" && echo \"$ORAS_FILE_NAME\" >> " + logPath + "\n"
+	if err := os.WriteFile(hook, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	outputDir := t.TempDir()
+	dst, err := file.New(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+	printer := output.NewPrinter(io.Discard, io.Discard, false)
+	opts := &pullOptions{includedNames: []string{"hi.txt"}, fileHook: hook, Output: outputDir}
+	opts.Reference = "v1"
+	if _, err := doPull(ctx, src, dst, oras.DefaultCopyOptions, text.NewPullHandler(printer), status.NewTextPullHandler(printer), opts); err != nil {
+		t.Fatal("doPull() error =", err)
+	}
+	got, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "hi.txt\n"; string(got) != want {
+		t.Errorf("hooked files = %q, want %q", got, want)
+	}
+}
+
+func Test_pullOptions_layerFilter(t *testing.T) {
+	layer := func(name, mediaType string) ocispec.Descriptor {
+		return ocispec.Descriptor{MediaType: mediaType, Annotations: map[string]string{ocispec.AnnotationTitle: name}}
+	}
+	yaml := layer("configs/app.yaml", "text/yaml")
+	sig := layer("configs/app.yaml.sig", "application/vnd.sig")
+	readme := layer("README.md", "text/markdown")
+	tests := []struct {
+		name string
+		opts pullOptions
+		want []bool
+	}{
+		{name: "include names", opts: pullOptions{includedNames: []string{"configs/*"}}, want: []bool{true, true, false}},
+		{name: "exclude names", opts: pullOptions{excludedNames: []string{"*.sig", "configs/*.sig"}}, want: []bool{true, false, true}},
+		{name: "include and exclude names", opts: pullOptions{includedNames: []string{"configs/*"}, excludedNames: []string{"configs/*.sig"}}, want: []bool{true, false, false}},
+		{name: "include media types and names", opts: pullOptions{includedMediaTypes: []string{"text/yaml", "text/markdown"}, includedNames: []string{"configs/*"}}, want: []bool{true, false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keep := tt.opts.layerFilter()
+			for i, desc := range []ocispec.Descriptor{yaml, sig, readme} {
+				if got := keep(desc); got != tt.want[i] {
+					t.Errorf("layerFilter()(%s) = %v, want %v", desc.Annotations[ocispec.AnnotationTitle], got, tt.want[i])
+				}
+			}
+		})
+	}
+	if keep := (&pullOptions{}).layerFilter(); keep != nil {
+		t.Error("layerFilter() without filter flags should return nil")
+	}
+}
+
+func Test_doPull_maxDownloadSize(t *testing.T) {
+	ctx := context.Background()
+	src := newPullTestSource(t)
+	// the layers are "hi.txt" and "sbom.json", 15 bytes in total
+	for _, tt := range []struct {
+		limit   int64
+		wantErr bool
+	}{
+		{limit: 14, wantErr: true},
+		{limit: 15, wantErr: false},
+	} {
+		outputDir := t.TempDir()
+		dst, err := file.New(outputDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		printer := output.NewPrinter(io.Discard, io.Discard, false)
+		opts := &pullOptions{maxDownloadSize: tt.limit, Output: outputDir}
+		opts.Reference = "v1"
+		_, err = doPull(ctx, src, dst, oras.DefaultCopyOptions, text.NewPullHandler(printer), status.NewTextPullHandler(printer), opts)
+		dst.Close()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("doPull() with limit %d error = %v, wantErr %v", tt.limit, err, tt.wantErr)
+		}
+		if _, statErr := os.Stat(filepath.Join(outputDir, "hi.txt")); tt.wantErr && !os.IsNotExist(statErr) {
+			t.Errorf("file is pulled despite the limit %d, stat error = %v", tt.limit, statErr)
+		}
+	}
+}
+
+// newPullTestIndexSource returns a memory store holding an index tagged "v1"
+// of two manifests, with the files "a.txt" of 13 bytes and "b.json" of 14
+// bytes respectively.
+func newPullTestIndexSource(t *testing.T) *memory.Store {
+	t.Helper()
+	ctx := context.Background()
+	src := memory.New()
+	var manifests []ocispec.Descriptor
+	for _, file := range []struct{ name, mediaType string }{
+		{"a.txt", "text/plain"},
+		{"b.json", "application/json"},
+	} {
+		blob := []byte(file.name + " content")
+		layer := content.NewDescriptorFromBytes(file.mediaType, blob)
+		layer.Annotations = map[string]string{ocispec.AnnotationTitle: file.name}
+		if err := src.Push(ctx, layer, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		manifest, err := oras.PackManifest(ctx, src, oras.PackManifestVersion1_1, "application/vnd.test", oras.PackManifestOptions{Layers: []ocispec.Descriptor{layer}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		manifests = append(manifests, manifest)
+	}
+	indexJSON, err := json.Marshal(ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: manifests,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, indexJSON)
+	if err := src.Push(ctx, index, bytes.NewReader(indexJSON)); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Tag(ctx, index, "v1"); err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+func Test_doPull_maxDownloadSize_index(t *testing.T) {
+	ctx := context.Background()
+	src := newPullTestIndexSource(t)
+
+	// the layers of the manifests are 27 bytes in total
+	for _, tt := range []struct {
+		limit   int64
+		wantErr bool
+	}{
+		{limit: 14, wantErr: true},
+		{limit: 27, wantErr: false},
+	} {
+		outputDir := t.TempDir()
+		dst, err := file.New(outputDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		printer := output.NewPrinter(io.Discard, io.Discard, false)
+		opts := &pullOptions{maxDownloadSize: tt.limit, Output: outputDir}
+		opts.Reference = "v1"
+		_, err = doPull(ctx, src, dst, oras.DefaultCopyOptions, text.NewPullHandler(printer), status.NewTextPullHandler(printer), opts)
+		dst.Close()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("doPull() with limit %d error = %v, wantErr %v", tt.limit, err, tt.wantErr)
+		}
+		if !tt.wantErr {
+			continue
+		}
+		for _, name := range []string{"a.txt", "b.json"} {
+			if _, statErr := os.Stat(filepath.Join(outputDir, name)); !os.IsNotExist(statErr) {
+				t.Errorf("%s is pulled despite the limit %d, stat error = %v", name, tt.limit, statErr)
+			}
+		}
+	}
+}
+
+// orderedTarget is a file store holding the copy of the manifest second until
+// the layer first starts being written, or for a while if it is never written.
+type orderedTarget struct {
+	*file.Store
+	first, second digest.Digest
+	pushed        chan struct{}
+	once          sync.Once
+	firstPushed   atomic.Bool
+}
+
+func (t *orderedTarget) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	if expected.Digest == t.first {
+		t.firstPushed.Store(true)
+		t.once.Do(func() { close(t.pushed) })
+	}
+	return t.Store.Push(ctx, expected, content)
+}
+
+func (t *orderedTarget) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	if target.Digest == t.second {
+		select {
+		case <-t.pushed:
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return t.Store.Exists(ctx, target)
+}
+
+func Test_doPull_allowedMediaTypes_index(t *testing.T) {
+	ctx := context.Background()
+	src := newPullTestIndexSource(t)
+	index, err := src.Resolve(ctx, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifests, err := content.Successors(ctx, src, index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers, err := content.Successors(ctx, src, manifests[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outputDir := t.TempDir()
+	store, err := file.New(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	// without the check upfront, "a.txt" of the first manifest would be
+	// written before "b.json" of the second manifest is rejected
+	dst := &orderedTarget{
+		Store:  store,
+		first:  layers[len(layers)-1].Digest,
+		second: manifests[1].Digest,
+		pushed: make(chan struct{}),
+	}
+	printer := output.NewPrinter(io.Discard, io.Discard, false)
+	opts := &pullOptions{allowedMediaTypes: []string{"text/plain"}, Output: outputDir}
+	opts.Reference = "v1"
+	_, err = doPull(ctx, src, dst, oras.DefaultCopyOptions, text.NewPullHandler(printer), status.NewTextPullHandler(printer), opts)
+	if !errors.Is(err, errUnsupportedMediaType) || !strings.Contains(err.Error(), "b.json") {
+		t.Fatalf("doPull() error = %v, want %v listing b.json", err, errUnsupportedMediaType)
+	}
+	if dst.firstPushed.Load() {
+		t.Error("a.txt is pulled despite the rejection")
+	}
+}
+
+// downloadRecorder records the names of the files in the order they start
+// downloading.
+type downloadRecorder struct {
+	status.PullHandler
+	names []string
+}
+
+func (r *downloadRecorder) OnNodeDownloading(desc ocispec.Descriptor) error {
+	if name := desc.Annotations[ocispec.AnnotationTitle]; name != "" {
+		r.names = append(r.names, name)
+	}
+	return r.PullHandler.OnNodeDownloading(desc)
+}
+
+func Test_doPull_downloadOrder(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	var layers []ocispec.Descriptor
+	for _, blob := range []string{"large.txt", "s.txt"} {
+		desc := content.NewDescriptorFromBytes("text/plain", []byte(blob))
+		desc.Annotations = map[string]string{ocispec.AnnotationTitle: blob}
+		if err := src.Push(ctx, desc, strings.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+		layers = append(layers, desc)
+	}
+	root, err := oras.PackManifest(ctx, src, oras.PackManifestVersion1_1, "application/vnd.test", oras.PackManifestOptions{Layers: layers})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Tag(ctx, root, "v1"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{order: orderManifest, want: []string{"large.txt", "s.txt"}},
+		{order: orderSmallestFirst, want: []string{"s.txt", "large.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			outputDir := t.TempDir()
+			dst, err := file.New(outputDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer dst.Close()
+			printer := output.NewPrinter(io.Discard, io.Discard, false)
+			recorder := &downloadRecorder{PullHandler: status.NewTextPullHandler(printer)}
+			// a single worker starts the layers in the order they are found
+			copyOpts := oras.DefaultCopyOptions
+			copyOpts.Concurrency = 1
+			opts := &pullOptions{downloadOrder: tt.order, Output: outputDir}
+			opts.Reference = "v1"
+			if _, err := doPull(ctx, src, dst, copyOpts, text.NewPullHandler(printer), recorder, opts); err != nil {
+				t.Fatal("doPull() error =", err)
+			}
+			if !reflect.DeepEqual(recorder.names, tt.want) {
+				t.Errorf("download order = %v, want %v", recorder.names, tt.want)
+			}
+		})
+	}
+}
+
+func Test_doPull_allowedMediaTypes(t *testing.T) {
+	ctx := context.Background()
+	src := newPullTestSource(t)
+	for _, tt := range []struct {
+		allowed []string
+		wantErr bool
+	}{
+		{allowed: []string{"text/plain"}, wantErr: true},
+		{allowed: []string{"text/plain", "application/spdx+json"}, wantErr: false},
+	} {
+		outputDir := t.TempDir()
+		dst, err := file.New(outputDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		printer := output.NewPrinter(io.Discard, io.Discard, false)
+		opts := &pullOptions{allowedMediaTypes: tt.allowed, Output: outputDir}
+		opts.Reference = "v1"
+		_, err = doPull(ctx, src, dst, oras.DefaultCopyOptions, text.NewPullHandler(printer), status.NewTextPullHandler(printer), opts)
+		dst.Close()
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("doPull() allowing %v error = %v, wantErr %v", tt.allowed, err, tt.wantErr)
+		}
+		if !tt.wantErr {
+			continue
+		}
+		if !errors.Is(err, errUnsupportedMediaType) || !strings.Contains(err.Error(), "sbom.json") {
+			t.Errorf("doPull() error = %v, want %v listing sbom.json", err, errUnsupportedMediaType)
+		}
+		if _, statErr := os.Stat(filepath.Join(outputDir, "hi.txt")); !os.IsNotExist(statErr) {
+			t.Errorf("allowed file is pulled despite the rejection, stat error = %v", statErr)
+		}
+	}
+}
+
+func Test_doPullToStdout(t *testing.T) {
+	ctx := context.Background()
+	src := newPullTestSource(t)
+
+	var out bytes.Buffer
+	opts := &pullOptions{includedNames: []string{"sbom.json"}}
+	opts.Reference = "v1"
+	got, err := doPullToStdout(ctx, src, &out, opts)
+	if err != nil {
+		t.Fatal("doPullToStdout() error =", err)
+	}
+	if got.Annotations[ocispec.AnnotationTitle] != "sbom.json" || out.String() != "sbom.json" {
+		t.Errorf("doPullToStdout() = %v with content %q, want sbom.json", got, out.String())
+	}
+
+	opts.includedNames = nil
+	if _, err := doPullToStdout(ctx, src, io.Discard, opts); err == nil {
+		t.Error("doPullToStdout() with 2 layers error = nil, want error")
+	}
+}
+
+func Test_renamePulledFiles(t *testing.T) {
+	outputDir := t.TempDir()
+	for _, name := range []string{"build/a.txt", "build/sub/b.txt", "c.txt"} {
+		path := filepath.Join(outputDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts := &pullOptions{stripPrefix: "build/"}
+	if err := renamePulledFiles(outputDir, []string{"build/a.txt", "build/sub", "c.txt", "build/a.txt", "build/missing"}, opts); err != nil {
+		t.Fatal("renamePulledFiles() error =", err)
+	}
+	for _, name := range []string{"a.txt", "sub/b.txt", "c.txt"} {
+		if _, err := os.Stat(filepath.Join(outputDir, filepath.FromSlash(name))); err != nil {
+			t.Errorf("file %s is not found: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "build")); !os.IsNotExist(err) {
+		t.Errorf("emptied prefix directory is not removed, stat error = %v", err)
+	}
+
+	// renaming onto an existing file
+	if err := os.WriteFile(filepath.Join(outputDir, "build.txt"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(outputDir, "build"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, "build", "c.txt"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	opts.KeepOldFiles = true
+	if err := renamePulledFiles(outputDir, []string{"build/c.txt"}, opts); err == nil {
+		t.Error("renamePulledFiles() onto an existing file with KeepOldFiles error = nil, want error")
+	}
+
+	// pulling two files as the same name
+	opts.KeepOldFiles = false
+	for _, names := range [][]string{{"d.txt", "build/d.txt"}, {"build/d.txt", "d.txt"}} {
+		dir := t.TempDir()
+		for _, name := range names {
+			path := filepath.Join(dir, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(name), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := renamePulledFiles(dir, names, opts); !errors.Is(err, file.ErrDuplicateName) {
+			t.Errorf("renamePulledFiles(%v) error = %v, want %v", names, err, file.ErrDuplicateName)
+		}
+		for _, name := range names {
+			if got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name))); err != nil || string(got) != name {
+				t.Errorf("%s = %q, %v, want the pulled file kept", name, got, err)
+			}
+		}
+	}
+}
+
+func Test_pullOptions_translateName(t *testing.T) {
+	opts := &pullOptions{stripPrefix: "build"}
+	for name, want := range map[string]string{
+		"build/a.txt":   "a.txt",
+		"build/":        "build/",
+		"build":         "build",
+		"builder/a.txt": "builder/a.txt",
+		"a.txt":         "a.txt",
+	} {
+		if got := opts.translateName(name); got != want {
+			t.Errorf("translateName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
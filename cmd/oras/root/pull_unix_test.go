@@ -0,0 +1,80 @@
+//go:build freebsd || linux || netbsd || openbsd || solaris
+
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras/cmd/oras/internal/display/metadata/text"
+	"oras.land/oras/cmd/oras/internal/display/status"
+	"oras.land/oras/cmd/oras/internal/output"
+)
+
+func Test_doPull_fileHook(t *testing.T) {
+	ctx := context.Background()
+	src := newPullTestSource(t)
+	hookDir := t.TempDir()
+	logPath := filepath.Join(hookDir, "log")
+	writeHook := func(script string) string {
+		path := filepath.Join(hookDir, "hook.sh")
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0700); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	pull := func(hook string) error {
+		outputDir := t.TempDir()
+		dst, err := file.New(outputDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer dst.Close()
+		printer := output.NewPrinter(io.Discard, io.Discard, false)
+		opts := &pullOptions{fileHook: hook, Output: outputDir}
+		opts.Reference = "v1"
+		_, err = doPull(ctx, src, dst, oras.DefaultCopyOptions, text.NewPullHandler(printer), status.NewTextPullHandler(printer), opts)
+		return err
+	}
+
+	// audit
+	hook := writeHook(`[ "$(cat "$ORAS_FILE_PATH")" = "$ORAS_FILE_NAME" ] && echo "$ORAS_FILE_NAME $ORAS_FILE_MEDIA_TYPE" >> ` + logPath)
+	if err := pull(hook); err != nil {
+		t.Fatal("doPull() error =", err)
+	}
+	log, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"hi.txt text/plain\n", "sbom.json application/spdx+json\n"} {
+		if !strings.Contains(string(log), want) {
+			t.Errorf("file hook log = %q, want %q", log, want)
+		}
+	}
+
+	// rejection
+	if err := pull(writeHook(`[ "$ORAS_FILE_NAME" != sbom.json ]`)); err == nil {
+		t.Error("doPull() with a failing file hook error = nil, want error")
+	}
+}
@@ -16,15 +16,29 @@ limitations under the License.
 package root
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
 	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/content/file"
 	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
 	"oras.land/oras-go/v2/registry/remote/auth"
 	"oras.land/oras/cmd/oras/internal/argument"
 	"oras.land/oras/cmd/oras/internal/command"
@@ -36,6 +50,7 @@ import (
 	"oras.land/oras/internal/contentutil"
 	"oras.land/oras/internal/listener"
 	"oras.land/oras/internal/registryutil"
+	"oras.land/oras/internal/speed"
 )
 
 type pushOptions struct {
@@ -44,11 +59,64 @@ type pushOptions struct {
 	option.ImageSpec
 	option.Target
 	option.Format
+	option.Platform
+
+	extraRefs          []string
+	manifestConfigRef  string
+	artifactType       string
+	concurrency        int
+	excludedMediaTypes []string
+	predecessors       []string
+	sidecarSuffix      string
+	speedReport        bool
+	splitSize          int64
+	gitPath            string
+	gitLenient         bool
+	continueOnError    bool
+	createdFromModTime bool
+	preserveAttributes bool
+	preserveSymlinks   bool
+	tarPath            string
+	ignoreFile         string
+	excludes           []string
+	excludePatterns    []ignorePattern
+	stdinName          string
+	onDuplicate        string
+	statusEvents       bool
+	chunkSize          int64
+	sessionFile        string
+	compression        string
+	manifestHook       string
+	dryRun             bool
+	reproducible       bool
+	subject            string
+	subjectRef         string
+	mountFrom          []string
+	mountRepos         []string
+	capabilities       []string
+}
+
+// annotationPredecessors is the manifest annotation key listing the references
+// of the artifacts that the pushed artifact is built from.
+const annotationPredecessors = "land.oras.artifact.predecessors"
 
-	extraRefs         []string
-	manifestConfigRef string
-	artifactType      string
-	concurrency       int
+// minRequestsPerSecond is the lowest non-zero value of --requests-per-second,
+// keeping the interval between two requests within the range of time.Duration.
+const minRequestsPerSecond = 0.001
+
+// layerFilter returns the predicate deciding which loaded layers are kept, or
+// nil if no layer is excluded.
+func (opts *pushOptions) layerFilter() func(desc ocispec.Descriptor) bool {
+	if len(opts.excludedMediaTypes) == 0 && len(opts.excludePatterns) == 0 {
+		return nil
+	}
+	return func(desc ocispec.Descriptor) bool {
+		if slices.Contains(opts.excludedMediaTypes, desc.MediaType) {
+			return false
+		}
+		name := desc.Annotations[ocispec.AnnotationTitle]
+		return name == "" || !isIgnored(opts.excludePatterns, name, desc.Annotations[file.AnnotationUnpack] == "true")
+	}
 }
 
 func pushCmd() *cobra.Command {
@@ -64,6 +132,12 @@ Example - Push file "hi.txt" with media type "application/vnd.oci.image.layer.v1
 Example - Push file "hi.txt" and export the pushed manifest to a specified path:
   oras push --export-manifest manifest.json localhost:5000/hello:v1 hi.txt
 
+Example - Push file "hi.txt" and print the pushed manifest descriptor with its layers in JSON:
+  oras push --format json localhost:5000/hello:v1 hi.txt
+
+Example - Push file "hi.txt" and print the digest of the pushed manifest only:
+  oras push --format go-template='{{.digest}}' localhost:5000/hello:v1 hi.txt
+
 Example - Push file "hi.txt" with the custom media type "application/vnd.me.hi":
   oras push localhost:5000/hello:v1 hi.txt:application/vnd.me.hi
 
@@ -91,12 +165,111 @@ Example - Push repository with manifest annotations:
 Example - Push repository with manifest annotation file:
   oras push --annotation-file annotation.json localhost:5000/hello:v1
 
+Example - Push files with the annotations of the keys of the annotation file matching their names, such as "*.sig" or "docs/**":
+  oras push --annotation-file annotation.json localhost:5000/hello:v1 app.bin app.bin.sig docs
+
+Example - [Preview] Push files excluding the ones of media type "application/vnd.me.tmp":
+  oras push --exclude-media-type application/vnd.me.tmp localhost:5000/hello:v1 hi.txt tmp.txt:application/vnd.me.tmp
+
+Example - Push file "hi.txt" and record the artifact it is built from:
+  oras push --predecessor localhost:5000/base@sha256:9463e0d192846bc994279417b50114606712d516aab45f4d8b31cbc6e46aad71 localhost:5000/hello:v1 hi.txt
+
+Example - Push file "hi.txt" with file annotations read from the sidecar file "hi.txt.annotations.json":
+  oras push --annotation-sidecar-suffix .annotations.json localhost:5000/hello:v1 hi.txt
+
+Example - [Preview] Push file "hi.txt" and the other text files, pushing "hi.txt" once although matched twice:
+  oras push --on-duplicate skip localhost:5000/hello:v1 hi.txt *.txt
+
 Example - Push file "hi.txt" with multiple tags:
   oras push localhost:5000/hello:tag1,tag2,tag3 hi.txt
 
 Example - Push file "hi.txt" with multiple tags and concurrency level tuned:
   oras push --concurrency 6 localhost:5000/hello:tag1,tag2,tag3 hi.txt
 
+Example - [Preview] Push the directory "site" so that pushing the same content again produces the same manifest digest:
+  oras push --reproducible localhost:5000/hello:v1 site
+
+Example - [Preview] Push the directory "site" without the files matching the patterns of "site/.orasignore":
+  oras push localhost:5000/hello:v1 site
+
+Example - [Preview] Push the directory "dist" without its temporary files and its ".git" directory:
+  oras push --exclude '*.tmp' --exclude '.git/' localhost:5000/hello:v1 dist
+
+Example - [Preview] Push the directory "site" with all its files, ignoring "site/.orasignore":
+  oras push --ignore-file "" localhost:5000/hello:v1 site
+
+Example - [Preview] Push the content read from stdin as the file "report.json" of type "application/json":
+  generate-report | oras push --stdin-name report.json localhost:5000/hello:v1 -- -:application/json
+
+Example - [Preview] Push the files of the tar archive "files.tar", named as in the archive:
+  oras push --tar files.tar localhost:5000/hello:v1
+
+Example - [Preview] Push the files of a tar archive read from stdin:
+  tar -c -C build . | oras push --tar - localhost:5000/hello:v1
+
+Example - [Preview] Push the symbolic link "latest" as a link rather than the file it points to:
+  oras push --preserve-symlinks localhost:5000/hello:v1 latest app-1.2.bin
+
+Example - [Preview] Push the executable "run.sh" with its mode, ownership and modification time:
+  oras push --preserve-attributes localhost:5000/hello:v1 run.sh
+
+Example - [Preview] Print the digest of the manifest that pushing file "hi.txt" would produce, without pushing anything:
+  oras push --dry-run localhost:5000/hello:v1 hi.txt
+
+Example - [Preview] Push file "sbom.json" as an artifact referring to the image "localhost:5000/hello:v1" as its subject:
+  oras push --artifact-type application/spdx+json --subject localhost:5000/hello:v1 localhost:5000/hello:sbom sbom.json
+
+Example - [Preview] Push file "hi.txt", mounting the blobs already uploaded to the repository "localhost:5000/base" instead of uploading them:
+  oras push --mount-from localhost:5000/base localhost:5000/hello:v1 hi.txt
+
+Example - [Preview] Push file "hi.txt" with the manifest rewritten by the executable "sign.sh" before uploading:
+  oras push --manifest-hook ./sign.sh localhost:5000/hello:v1 hi.txt
+
+Example - [Preview] Push file "hi.txt" compressed with gzip as the layer "hi.txt.gz":
+  oras push --compress gzip localhost:5000/hello:v1 hi.txt
+
+Example - [Preview] Push file "large.bin" uploaded in chunks of 10 MB over an unstable link:
+  oras push --chunk-size 10000000 localhost:5000/hello:v1 large.bin
+
+Example - [Preview] Push file "large.bin" in chunks of 10 MB, resuming the uploads interrupted by a previous run of the same command:
+  oras push --chunk-size 10000000 --session-file push.session localhost:5000/hello:v1 large.bin
+
+Example - [Preview] Push file "hi.txt" and print the upload status events as JSON lines to stderr:
+  oras push --status-events localhost:5000/hello:v1 hi.txt
+
+Example - [Preview] Push the directory "site" with the created time of the manifest set to the latest modification time of its files:
+  oras push --created-from-mtime localhost:5000/hello:v1 site
+
+Example - [Preview] Push files "a.bin" and "b.bin", uploading all the blobs that can be uploaded even if some fail:
+  oras push --continue-on-error localhost:5000/hello:v1 a.bin b.bin
+
+Example - [Preview] Push file "hi.txt" with annotations of the Git commit checked out in the current directory:
+  oras push --git-annotations . localhost:5000/hello:v1 hi.txt
+
+Example - [Preview] Push file "large.bin" split into layers of at most 100 MB, so that unchanged parts are deduplicated across versions:
+  oras push --split-size 100000000 localhost:5000/hello:v1 large.bin
+
+Example - [Preview] Push file "hi.txt" only if the registry supports the referrers API:
+  oras push --require-capability referrers-api localhost:5000/hello:v1 hi.txt
+
+Example - [Preview] Push file "hi.txt" and report the upload throughput and request latencies:
+  oras push --speed-report localhost:5000/hello:v1 hi.txt
+
+Example - [Preview] Push file "hi.txt" and print the bytes uploaded and skipped, the elapsed time and the throughput in JSON:
+  oras push --speed-report --format json localhost:5000/hello:v1 hi.txt
+
+Example - [Preview] Push file "large.bin", cancelling and retrying the upload of any blob taking more than 10 minutes:
+  oras push --blob-timeout 10m localhost:5000/hello:v1 large.bin
+
+Example - [Preview] Push file "hi.txt" to a flaky registry, retrying failed requests up to 10 times after waiting from 1 second to 30 seconds:
+  oras push --max-retries 10 --retry-min-wait 1s --retry-max-wait 30s localhost:5000/hello:v1 hi.txt
+
+Example - [Preview] Push file "hi.txt" sending at most 10 HTTP requests per second:
+  oras push --requests-per-second 10 localhost:5000/hello:v1 hi.txt
+
+Example - [Preview] Push file "hi.txt" for the platform linux/arm64, to be added to an index by "oras manifest index create":
+  oras push --platform linux/arm64 localhost:5000/hello:linux-arm64 hi.txt
+
 Example - Push file "hi.txt" into an OCI image layout folder 'layout-dir' with tag 'test':
   oras push --oci-layout layout-dir:test hi.txt
 `,
@@ -109,7 +282,82 @@ Example - Push file "hi.txt" into an OCI image layout folder 'layout-dir' with t
 			if err := option.Parse(cmd, &opts); err != nil {
 				return err
 			}
+			for _, capability := range opts.capabilities {
+				if !slices.Contains(registryutil.Capabilities, capability) {
+					return fmt.Errorf("unknown capability %q for --require-capability, options: %s", capability, strings.Join(registryutil.Capabilities, ", "))
+				}
+			}
+			if len(opts.capabilities) != 0 && opts.Target.Type == option.TargetTypeOCILayout {
+				return errors.New("--require-capability cannot be used when pushing to an OCI image layout")
+			}
+			if err := oerrors.CheckMutuallyExclusiveFlags(cmd.Flags(), "dry-run", "require-capability"); err != nil {
+				return err
+			}
+			if err := oerrors.CheckMutuallyExclusiveFlags(cmd.Flags(), "dry-run", "subject"); err != nil {
+				return err
+			}
+			if err := opts.parseMountFrom(); err != nil {
+				return err
+			}
+			if err := opts.parseSubject(); err != nil {
+				return err
+			}
+			if stdinRefs := slices.DeleteFunc(slices.Clone(opts.FileRefs), func(ref string) bool {
+				return !isStdinRef(ref)
+			}); len(stdinRefs) != 0 {
+				if len(stdinRefs) > 1 {
+					return errors.New("stdin can only be pushed once")
+				}
+				if opts.compression != "" || opts.splitSize > 0 || opts.preserveAttributes {
+					return errors.New("--compress, --split-size and --preserve-attributes cannot be used when pushing stdin")
+				}
+			}
+			if opts.tarPath != "" {
+				if len(opts.FileRefs) != 0 {
+					return errors.New("--tar cannot be used with files to push")
+				}
+				if err := oerrors.CheckMutuallyExclusiveFlags(cmd.Flags(), "tar", "compress", "split-size", "preserve-attributes", "preserve-symlinks", "created-from-mtime", "annotation-sidecar-suffix"); err != nil {
+					return err
+				}
+			}
+			if opts.gitLenient && opts.gitPath == "" {
+				return errors.New("--git-annotations-lenient requires --git-annotations")
+			}
+			if opts.compression != "" && opts.compression != compressionGzip {
+				return fmt.Errorf("unsupported compression %q for --compress, options: %s", opts.compression, compressionGzip)
+			}
+			if opts.chunkSize < 0 {
+				return fmt.Errorf("invalid value %d for --chunk-size: must not be negative", opts.chunkSize)
+			}
+			if opts.chunkSize > 0 && opts.Target.Type == option.TargetTypeOCILayout {
+				return errors.New("--chunk-size cannot be used when pushing to an OCI image layout")
+			}
+			if !slices.Contains(onDuplicatePolicies, opts.onDuplicate) {
+				return fmt.Errorf("unsupported policy %q for --on-duplicate, options: %s", opts.onDuplicate, strings.Join(onDuplicatePolicies, ", "))
+			}
+			if opts.sessionFile != "" && opts.chunkSize == 0 {
+				return errors.New("--session-file requires --chunk-size")
+			}
+			if opts.splitSize < 0 {
+				return fmt.Errorf("invalid value %d for --split-size: must not be negative", opts.splitSize)
+			}
+			if opts.RequestsPerSecond != 0 && !(opts.RequestsPerSecond >= minRequestsPerSecond) {
+				return fmt.Errorf("invalid value %v for --requests-per-second: must be 0 or at least %v", opts.RequestsPerSecond, minRequestsPerSecond)
+			}
+			patterns, err := compileExcludePatterns(opts.excludes)
+			if err != nil {
+				return err
+			}
+			opts.excludePatterns = patterns
+			for _, ref := range opts.predecessors {
+				if _, err := registry.ParseReference(ref); err != nil {
+					return fmt.Errorf("invalid predecessor %q: %w", ref, err)
+				}
+			}
 
+			if opts.Platform.Platform != nil && opts.manifestConfigRef != "" {
+				return errors.New("--platform and --config cannot both be provided")
+			}
 			if opts.manifestConfigRef != "" && opts.artifactType == "" {
 				if !cmd.Flags().Changed("image-spec") {
 					// switch to v1.0 manifest since artifact type is suggested
@@ -130,6 +378,12 @@ Example - Push file "hi.txt" into an OCI image layout folder 'layout-dir' with t
 				if opts.manifestConfigRef != "" && opts.artifactType != "" {
 					return errors.New("--artifact-type and --config cannot both be provided for 1.0 OCI image")
 				}
+				if opts.subject != "" {
+					return &oerrors.Error{
+						Err:            errors.New("--subject is not supported by OCI image-spec v1.0 manifests"),
+						Recommendation: "set an artifact type via `--artifact-type` and push with image spec v1.1",
+					}
+				}
 			case oras.PackManifestVersion1_1:
 				if opts.manifestConfigRef == "" && opts.artifactType == "" {
 					opts.artifactType = oras.MediaTypeUnknownArtifact
@@ -144,21 +398,173 @@ Example - Push file "hi.txt" into an OCI image layout folder 'layout-dir' with t
 	cmd.Flags().StringVarP(&opts.manifestConfigRef, "config", "", "", "`path` of image config file")
 	cmd.Flags().StringVarP(&opts.artifactType, "artifact-type", "", "", "artifact type")
 	cmd.Flags().IntVarP(&opts.concurrency, "concurrency", "", 5, "concurrency level")
+	cmd.Flags().Float64VarP(&opts.RequestsPerSecond, "requests-per-second", "", 0, "[Preview] maximum number of HTTP requests sent to the registry per second, unlimited if 0")
+	cmd.Flags().BoolVarP(&opts.reproducible, "reproducible", "", false, "[Preview] produce the same manifest for the same content by sorting the layers by name, removing times from directory tarballs and setting the created time to the Unix epoch unless specified")
+	cmd.Flags().BoolVarP(&opts.dryRun, "dry-run", "", false, "[Preview] pack the manifest and print its digest without connecting to the registry or pushing anything")
+	cmd.Flags().StringVarP(&opts.subject, "subject", "", "", "[Preview] `reference` of the manifest in the same repository that the pushed artifact refers to as its subject, like oras attach")
+	cmd.Flags().StringArrayVarP(&opts.mountFrom, "mount-from", "", nil, "[Preview] `repository` in the same registry to mount existing blobs from before uploading them, tried in turn")
+	cmd.Flags().StringVarP(&opts.manifestHook, "manifest-hook", "", "", "[Preview] `path` of an executable run with the packed manifest on stdin before uploading it, a non-empty stdout replaces the manifest and a non-zero exit status aborts the push")
+	cmd.Flags().StringVarP(&opts.compression, "compress", "", "", "[Preview] compress the pushed files with the `algorithm` into layers named with its extension, options: gzip")
+	cmd.Flags().Int64VarP(&opts.chunkSize, "chunk-size", "", 0, "[Preview] upload blobs larger than `bytes` in chunks of that size, resuming interrupted chunks")
+	cmd.Flags().StringVarP(&opts.sessionFile, "session-file", "", "", "[Preview] `path` of the file recording the chunked uploads in progress, so that pushing again resumes the interrupted uploads")
+	cmd.Flags().BoolVarP(&opts.statusEvents, "status-events", "", false, "[Preview] print the status of each file and blob as JSON lines to stderr instead of the status output")
+	cmd.Flags().BoolVarP(&opts.preserveAttributes, "preserve-attributes", "", false, "[Preview] record the mode, the ownership and the modification time of the pushed files as annotations, restored by pull --preserve-attributes")
+	cmd.Flags().BoolVarP(&opts.preserveSymlinks, "preserve-symlinks", "", false, "[Preview] push the symbolic links among the files as links instead of the files they point to, restored by pull --preserve-symlinks")
+	cmd.Flags().StringVarP(&opts.ignoreFile, "ignore-file", "", defaultIgnoreFile, "[Preview] `name` of the file listing the gitignore patterns of the files skipped when pushing the directory containing it, ignore files are not read if empty")
+	cmd.Flags().StringArrayVarP(&opts.excludes, "exclude", "", nil, "[Preview] skip the files and the files of the pushed directories whose path matches the gitignore `pattern`")
+	cmd.Flags().StringVarP(&opts.onDuplicate, "on-duplicate", "", onDuplicateError, fmt.Sprintf("[Preview] `policy` for the files whose name is already pushed, options: %s", strings.Join(onDuplicatePolicies, ", ")))
+	cmd.Flags().StringVarP(&opts.stdinName, "stdin-name", "", "stdin", "[Preview] `name` of the file read from stdin when - is given as a file to push")
+	cmd.Flags().StringVarP(&opts.tarPath, "tar", "", "", "[Preview] push the files of the tar archive at `path` instead of files on disk, use - for stdin")
+	cmd.Flags().BoolVarP(&opts.createdFromModTime, "created-from-mtime", "", false, "[Preview] set the created time of the manifest to the latest modification time of the pushed files, unless specified via --annotation")
+	cmd.Flags().BoolVarP(&opts.continueOnError, "continue-on-error", "", false, "[Preview] keep uploading the remaining blobs if a blob fails to upload, and skip the manifest if any failed")
+	cmd.Flags().StringVarP(&opts.gitPath, "git-annotations", "", "", "[Preview] add manifest annotations of the revision, source, branch and dirty state of the Git working tree at `path`")
+	cmd.Flags().BoolVarP(&opts.gitLenient, "git-annotations-lenient", "", false, "[Preview] skip the Git annotations instead of failing if the path of --git-annotations is not a Git working tree")
+	cmd.Flags().Int64VarP(&opts.splitSize, "split-size", "", 0, "[Preview] split files larger than `bytes` into multiple layers, reassembled by oras pull")
+	cmd.Flags().StringArrayVarP(&opts.capabilities, "require-capability", "", nil, fmt.Sprintf("[Preview] fail before uploading if the registry lacks the `capability`, options: %s", strings.Join(registryutil.Capabilities, ", ")))
+	cmd.Flags().BoolVarP(&opts.speedReport, "speed-report", "", false, "[Preview] print the throughput of the uploaded blobs, the size of the skipped ones and the latency of the requests to stderr after pushing, and add the summary of the uploads to the --format output")
+	cmd.Flags().StringArrayVarP(&opts.excludedMediaTypes, "exclude-media-type", "", nil, "[Preview] exclude files of the `media type` from the pushed artifact")
+	cmd.Flags().StringVarP(&opts.sidecarSuffix, "annotation-sidecar-suffix", "", "", "[Preview] load file annotations from JSON files named as the pushed files with the `suffix` appended")
+	cmd.Flags().StringArrayVarP(&opts.predecessors, "predecessor", "", nil, "[Preview] `reference` of an artifact that the pushed artifact is built from")
 	opts.SetTypes(option.FormatTypeText, option.FormatTypeJSON, option.FormatTypeGoTemplate)
+	opts.EnableRetryFlags()
+	opts.FlagDescription = "[Preview] record the platform of the pushed artifact in the config"
 	option.ApplyFlags(&opts, cmd.Flags())
 	return oerrors.Command(cmd, &opts.Target)
 }
 
+// parseSubject parses the reference of --subject, which is a tag or a digest,
+// or a full reference in the same repository as the pushed artifact.
+func (opts *pushOptions) parseSubject() error {
+	opts.subjectRef = opts.subject
+	if opts.subject == "" || opts.Target.Type != option.TargetTypeRemote || !strings.Contains(opts.subject, "/") {
+		return nil
+	}
+	target, err := registry.ParseReference(opts.RawReference)
+	if err != nil {
+		return err
+	}
+	ref, err := registry.ParseReference(opts.subject)
+	if err != nil {
+		return fmt.Errorf("invalid subject %q: %w", opts.subject, err)
+	}
+	if ref.Reference == "" {
+		return fmt.Errorf("invalid subject %q: tag or digest is required", opts.subject)
+	}
+	if ref.Registry != target.Registry || ref.Repository != target.Repository {
+		return &oerrors.Error{
+			Err:            fmt.Errorf("invalid subject %q: not in the repository %s/%s", opts.subject, target.Registry, target.Repository),
+			Recommendation: "The subject must be in the same repository as the pushed artifact",
+		}
+	}
+	opts.subjectRef = ref.Reference
+	return nil
+}
+
+// parseMountFrom validates the repositories of --mount-from, which must be in
+// the same registry as the pushed artifact.
+func (opts *pushOptions) parseMountFrom() error {
+	if len(opts.mountFrom) == 0 {
+		return nil
+	}
+	if opts.Target.Type == option.TargetTypeOCILayout {
+		return errors.New("--mount-from cannot be used when pushing to an OCI image layout")
+	}
+	target, err := registry.ParseReference(opts.RawReference)
+	if err != nil {
+		return err
+	}
+	opts.mountRepos = nil
+	for _, from := range opts.mountFrom {
+		ref, err := registry.ParseReference(from)
+		if err != nil {
+			return fmt.Errorf("invalid repository %q for --mount-from: %w", from, err)
+		}
+		if ref.Reference != "" {
+			return fmt.Errorf("invalid repository %q for --mount-from: tag or digest is not allowed", from)
+		}
+		if ref.Registry != target.Registry {
+			return &oerrors.Error{
+				Err:            fmt.Errorf("cannot mount blobs from %q: registry %s differs from %s", from, ref.Registry, target.Registry),
+				Recommendation: "Blobs can only be mounted across repositories of the same registry",
+			}
+		}
+		opts.mountRepos = append(opts.mountRepos, ref.Repository)
+	}
+	return nil
+}
+
+// loadFileRef adds the i-th file reference to store. Stdin, symbolic links
+// and directories with ignored files are copied into tempDir first. ok is
+// false if the file is skipped.
+func (opts *pushOptions) loadFileRef(ctx context.Context, store *file.Store, sources fileSources, annotations map[string]map[string]string, i int, fileRef string, stdin io.Reader, tempDir string, displayStatus status.PushHandler) (desc ocispec.Descriptor, ok bool, err error) {
+	if isStdinRef(fileRef) {
+		desc, err := loadStdin(ctx, store, sources, annotations, fileRef, opts.stdinName, stdin, filepath.Join(tempDir, "stdin"), displayStatus)
+		return desc, err == nil, err
+	}
+	if opts.preserveSymlinks {
+		if desc, ok, err := loadSymlink(ctx, store, sources, annotations, fileRef, filepath.Join(tempDir, strconv.Itoa(i)+".link"), displayStatus); ok || err != nil {
+			return desc, ok, err
+		}
+	}
+	if opts.ignoreFile != "" || len(opts.excludePatterns) != 0 {
+		if desc, ok, err := loadIgnoringDir(ctx, store, sources, annotations, fileRef, opts.ignoreFile, opts.excludePatterns, filepath.Join(tempDir, strconv.Itoa(i)+".dir"), displayStatus); ok || err != nil {
+			return desc, ok, err
+		}
+	}
+	return loadFile(ctx, store, sources, annotations, fileRef, opts.onDuplicate, displayStatus)
+}
+
+// pushPlatformConfig pushes a config of mediaType holding platform to storage,
+// in the format of the image config read by oras manifest index create.
+func pushPlatformConfig(ctx context.Context, storage content.Pusher, mediaType string, platform ocispec.Platform) (ocispec.Descriptor, error) {
+	configBytes, err := json.Marshal(platform)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	desc := content.NewDescriptorFromBytes(mediaType, configBytes)
+	if err := storage.Push(ctx, desc, bytes.NewReader(configBytes)); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return desc, nil
+}
+
 func runPush(cmd *cobra.Command, opts *pushOptions) error {
 	ctx, logger := command.GetLogger(cmd, &opts.Common)
 	displayStatus, displayMetadata, err := display.NewPushHandler(opts.Printer, opts.Format, opts.TTY)
 	if err != nil {
 		return err
 	}
+	if opts.statusEvents {
+		displayStatus = status.NewEventPushHandler(cmd.ErrOrStderr())
+	}
 	annotations, err := opts.LoadManifestAnnotations()
 	if err != nil {
 		return err
 	}
+	if opts.sidecarSuffix != "" {
+		annotations, err = loadSidecarAnnotations(annotations, opts.FileRefs, opts.sidecarSuffix)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.createdFromModTime {
+		annotations, err = setCreatedFromModTime(annotations, opts.FileRefs)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.gitPath != "" {
+		annotations, err = addGitAnnotations(ctx, annotations, opts.gitPath, opts.gitLenient)
+		if err != nil {
+			return err
+		}
+	}
+	if len(opts.predecessors) != 0 {
+		annotations, err = setManifestAnnotation(annotations, annotationPredecessors, strings.Join(opts.predecessors, ","))
+		if err != nil {
+			return err
+		}
+	}
 
 	// prepare pack
 	packOpts := oras.PackManifestOptions{
@@ -170,53 +576,214 @@ func runPush(cmd *cobra.Command, opts *pushOptions) error {
 		return err
 	}
 	defer store.Close()
+	store.TarReproducible = opts.reproducible
 	if opts.manifestConfigRef != "" {
 		path, cfgMediaType, err := fileref.Parse(opts.manifestConfigRef, oras.MediaTypeUnknownConfig)
 		if err != nil {
 			return err
 		}
-		desc, err := addFile(ctx, store, option.AnnotationConfig, cfgMediaType, path)
+		desc, err := addFile(ctx, store, nil, option.AnnotationConfig, cfgMediaType, path)
+		if err != nil {
+			return err
+		}
+		desc.Annotations = packOpts.ConfigAnnotations
+		packOpts.ConfigDescriptor = &desc
+	} else if opts.Platform.Platform != nil {
+		mediaType := oras.MediaTypeUnknownConfig
+		if opts.PackVersion == oras.PackManifestVersion1_0 && opts.artifactType != "" {
+			mediaType = opts.artifactType
+		}
+		desc, err := pushPlatformConfig(ctx, store, mediaType, *opts.Platform.Platform)
 		if err != nil {
 			return err
 		}
 		desc.Annotations = packOpts.ConfigAnnotations
 		packOpts.ConfigDescriptor = &desc
 	}
-	descs, err := loadFiles(ctx, store, annotations, opts.FileRefs, displayStatus)
+	tempDir, err := os.MkdirTemp("", "oras_push_*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+	sources := make(fileSources)
+	var descs []ocispec.Descriptor
+	if opts.tarPath != "" {
+		if descs, err = loadTar(ctx, store, sources, annotations, opts.tarPath, cmd.InOrStdin(), tempDir, displayStatus); err != nil {
+			return err
+		}
+	} else {
+		for i, fileRef := range opts.FileRefs {
+			desc, ok, err := opts.loadFileRef(ctx, store, sources, annotations, i, fileRef, cmd.InOrStdin(), tempDir, displayStatus)
+			if err != nil {
+				return err
+			}
+			if ok {
+				descs = append(descs, desc)
+			}
+		}
+		if len(descs) == 0 {
+			if err := displayStatus.OnEmptyArtifact(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := applyPatternAnnotations(annotations, descs); err != nil {
+		return err
+	}
+	descs, err = filterLayers(descs, opts.layerFilter())
 	if err != nil {
 		return err
 	}
+	if opts.preserveAttributes {
+		if err := setFileAttributes(sources, descs); err != nil {
+			return err
+		}
+	}
+	if opts.compression != "" {
+		if descs, err = compressLayers(ctx, store, sources, descs, tempDir); err != nil {
+			return err
+		}
+	}
+	if opts.splitSize > 0 {
+		if descs, err = splitLayers(ctx, store, sources, descs, opts.splitSize, tempDir); err != nil {
+			return err
+		}
+	}
+	if opts.reproducible {
+		if annotations, err = setReproducible(annotations, descs); err != nil {
+			return err
+		}
+		packOpts.ManifestAnnotations = annotations[option.AnnotationManifest]
+	}
 	packOpts.Layers = descs
 	memoryStore := memory.New()
+	if packOpts.ConfigDescriptor != nil {
+		if err := opts.CheckMetadataSize(*packOpts.ConfigDescriptor); err != nil {
+			return err
+		}
+	}
 	pack := func() (ocispec.Descriptor, error) {
 		root, err := oras.PackManifest(ctx, memoryStore, opts.PackVersion, opts.artifactType, packOpts)
 		if err != nil {
 			return ocispec.Descriptor{}, err
 		}
+		if opts.manifestHook != "" {
+			if root, err = runManifestHook(ctx, opts.manifestHook, memoryStore, root); err != nil {
+				return ocispec.Descriptor{}, err
+			}
+		}
+		if err := opts.CheckMetadataSize(root); err != nil {
+			return ocispec.Descriptor{}, err
+		}
 		if err = memoryStore.Tag(ctx, root, root.Digest.String()); err != nil {
 			return ocispec.Descriptor{}, err
 		}
 		return root, nil
 	}
 
+	if opts.dryRun {
+		root, err := pack()
+		if err != nil {
+			return err
+		}
+		if opts.Target.Type == option.TargetTypeRemote {
+			// the repository path is usually set when connecting to it
+			repo, err := registry.ParseReference(opts.RawReference)
+			if err != nil {
+				return err
+			}
+			repo.Reference = ""
+			opts.Path = repo.String()
+		}
+		if opts.Format.Type == option.FormatTypeText.Name {
+			err = opts.Println("Dry run, nothing is pushed to", opts.AnnotatedReference())
+		} else {
+			err = displayMetadata.OnCopied(&opts.Target)
+		}
+		if err != nil {
+			return err
+		}
+		layers, err := manifestLayers(ctx, memoryStore, root)
+		if err != nil {
+			return err
+		}
+		if err := displayMetadata.OnCompleted(root, layers); err != nil {
+			return err
+		}
+		return opts.ExportManifest(ctx, memoryStore, root)
+	}
+
 	// prepare push
+	var recorder *speed.Recorder
+	if opts.speedReport {
+		recorder = speed.NewRecorder()
+		opts.WrapTransport = recorder.Transport
+	}
 	originalDst, err := opts.NewTarget(opts.Common, logger)
 	if err != nil {
 		return err
 	}
-	dst, stopTrack, err := displayStatus.TrackTarget(originalDst)
+	if opts.subjectRef != "" {
+		subject, err := oras.Resolve(ctx, originalDst, opts.subjectRef, oras.DefaultResolveOptions)
+		if err != nil {
+			return fmt.Errorf("failed to resolve subject %s: %w", opts.subject, err)
+		}
+		packOpts.Subject = &subject
+	}
+	if repo, ok := originalDst.(*remote.Repository); ok {
+		if len(opts.capabilities) != 0 {
+			if err := registryutil.CheckCapabilities(ctx, repo, opts.capabilities); err != nil {
+				return err
+			}
+		}
+		if opts.chunkSize > 0 {
+			chunked := &registryutil.ChunkedRepository{Repository: repo, ChunkSize: opts.chunkSize}
+			if opts.sessionFile != "" {
+				if chunked.Sessions, err = registryutil.LoadUploadSessions(opts.sessionFile); err != nil {
+					return err
+				}
+			}
+			originalDst = chunked
+		}
+	}
+	union := contentutil.MultiReadOnlyTarget(memoryStore, store)
+	target := originalDst
+	if opts.BlobTimeout > 0 {
+		target = contentutil.NewTimeoutTarget(originalDst, union, opts.BlobTimeout, opts.MaxRetries())
+	}
+	dst, stopTrack, err := displayStatus.TrackTarget(target)
 	if err != nil {
 		return err
 	}
 	copyOptions := oras.DefaultCopyOptions
 	copyOptions.Concurrency = opts.concurrency
-	union := contentutil.MultiReadOnlyTarget(memoryStore, store)
 	displayStatus.UpdateCopyOptions(&copyOptions.CopyGraphOptions, union)
+	if len(opts.mountRepos) != 0 {
+		copyOptions.MountFrom = func(context.Context, ocispec.Descriptor) ([]string, error) {
+			return opts.mountRepos, nil
+		}
+	}
+	if recorder != nil {
+		recorder.UpdateCopyOptions(&copyOptions.CopyGraphOptions)
+	}
 	copy := func(root ocispec.Descriptor) error {
 		// add both pull and push scope hints for dst repository
 		// to save potential push-scope token requests during copy
 		ctx = registryutil.WithScopeHint(ctx, dst, auth.ActionPull, auth.ActionPush)
 
+		if opts.continueOnError {
+			blobs, err := content.Successors(ctx, union, root)
+			if err != nil {
+				return err
+			}
+			if err := uploadBlobs(ctx, union, dst, blobs, copyOptions.CopyGraphOptions); err != nil {
+				return err
+			}
+			// all the blobs are uploaded, only the manifest is left
+			copyOptions.FindSuccessors = func(context.Context, content.Fetcher, ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+				return nil, nil
+			}
+		}
 		if tag := opts.Reference; tag == "" {
 			err = oras.CopyGraph(ctx, union, dst, root, copyOptions.CopyGraphOptions)
 		} else {
@@ -248,15 +815,128 @@ func runPush(cmd *cobra.Command, opts *pushOptions) error {
 		}
 	}
 
-	err = displayMetadata.OnCompleted(root)
+	layers, err := manifestLayers(ctx, memoryStore, root)
+	if err != nil {
+		return err
+	}
+	if recorder != nil {
+		if err := displayMetadata.OnSummary(recorder.Summary()); err != nil {
+			return err
+		}
+	}
+	err = displayMetadata.OnCompleted(root, layers)
 	if err != nil {
 		return err
 	}
+	if recorder != nil {
+		if err := recorder.Report(cmd.ErrOrStderr()); err != nil {
+			return err
+		}
+	}
 
 	// Export manifest
 	return opts.ExportManifest(ctx, memoryStore, root)
 }
 
+// uploadBlobs uploads the blobs from src to dst. Unlike oras.CopyGraph, it
+// keeps uploading the remaining blobs after a failure and returns all the
+// failures at the end.
+func uploadBlobs(ctx context.Context, src content.ReadOnlyStorage, dst content.Storage, blobs []ocispec.Descriptor, opts oras.CopyGraphOptions) error {
+	errs := make([]error, len(blobs))
+	eg, egCtx := errgroup.WithContext(ctx)
+	if opts.Concurrency > 0 {
+		eg.SetLimit(opts.Concurrency)
+	}
+	for i, blob := range blobs {
+		eg.Go(func() error {
+			if err := oras.CopyGraph(egCtx, src, dst, blob, opts); err != nil {
+				name := blob.Annotations[ocispec.AnnotationTitle]
+				if name == "" {
+					name = blob.MediaType
+				}
+				errs[i] = fmt.Errorf("failed to upload %s (%s): %w", name, blob.Digest, err)
+			}
+			// never cancel the other uploads
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &oerrors.Error{
+		Err:            fmt.Errorf("%d of %d blobs failed to upload, the manifest is not uploaded:\n%w", len(failed), len(blobs), errors.Join(failed...)),
+		Recommendation: "Fix the failures and push again, the uploaded blobs will be skipped",
+	}
+}
+
+// runManifestHook runs hook with the content of the manifest root in storage
+// on stdin. If the hook writes to stdout, the output is pushed to storage as
+// the manifest replacing root.
+func runManifestHook(ctx context.Context, hook string, storage content.Storage, root ocispec.Descriptor) (ocispec.Descriptor, error) {
+	manifestBytes, err := content.FetchAll(ctx, storage, root)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, hook)
+	cmd.Env = append(os.Environ(),
+		"ORAS_MANIFEST_DIGEST="+root.Digest.String(),
+		"ORAS_MANIFEST_MEDIA_TYPE="+root.MediaType,
+	)
+	cmd.Stdin = bytes.NewReader(manifestBytes)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return ocispec.Descriptor{}, &oerrors.Error{
+			Err:            fmt.Errorf("push of manifest %s aborted by the manifest hook: %w", root.Digest, err),
+			Recommendation: fmt.Sprintf("Check the output of %s for why the manifest must not be pushed", hook),
+		}
+	}
+	if stdout.Len() == 0 {
+		return root, nil
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(stdout.Bytes(), &manifest); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("invalid manifest written by the manifest hook: %w", err)
+	}
+	if manifest.MediaType != root.MediaType {
+		return ocispec.Descriptor{}, fmt.Errorf("invalid manifest written by the manifest hook: media type %q does not match %q", manifest.MediaType, root.MediaType)
+	}
+	desc := content.NewDescriptorFromBytes(root.MediaType, stdout.Bytes())
+	desc.ArtifactType = manifest.ArtifactType
+	if desc.ArtifactType == "" {
+		desc.ArtifactType = manifest.Config.MediaType
+	}
+	desc.Annotations = manifest.Annotations
+	if err := storage.Push(ctx, desc, bytes.NewReader(stdout.Bytes())); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return ocispec.Descriptor{}, err
+	}
+	return desc, nil
+}
+
+// manifestLayers returns the layers of the manifest root in fetcher, which
+// may differ from the packed layers if rewritten by the manifest hook.
+func manifestLayers(ctx context.Context, fetcher content.Fetcher, root ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	manifestBytes, err := content.FetchAll(ctx, fetcher, root)
+	if err != nil {
+		return nil, err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, err
+	}
+	return manifest.Layers, nil
+}
+
 func doPush(dst oras.Target, stopTrack status.StopTrackTargetFunc, pack packFunc, copy copyFunc) (ocispec.Descriptor, error) {
 	defer func() {
 		_ = stopTrack()
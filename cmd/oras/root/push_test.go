@@ -16,12 +16,26 @@ limitations under the License.
 package root
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras-go/v2/content/memory"
 	"oras.land/oras/cmd/oras/internal/errors"
 	"oras.land/oras/cmd/oras/internal/option"
+	"oras.land/oras/cmd/oras/internal/output"
 )
 
 func Test_runPush_errType(t *testing.T) {
@@ -41,3 +55,424 @@ func Test_runPush_errType(t *testing.T) {
 		t.Fatalf("got %v, want %v", got, want)
 	}
 }
+
+// failingStorage is a memory store failing to push the content of failed.
+type failingStorage struct {
+	*memory.Store
+	failed digest.Digest
+}
+
+func (s *failingStorage) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	if expected.Digest == s.failed {
+		return io.ErrUnexpectedEOF
+	}
+	return s.Store.Push(ctx, expected, r)
+}
+
+func Test_uploadBlobs(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	var blobs []ocispec.Descriptor
+	for _, b := range []string{"foo", "bar", "baz"} {
+		desc := content.NewDescriptorFromBytes("test", []byte(b))
+		desc.Annotations = map[string]string{ocispec.AnnotationTitle: b + ".txt"}
+		if err := src.Push(ctx, desc, bytes.NewReader([]byte(b))); err != nil {
+			t.Fatal(err)
+		}
+		blobs = append(blobs, desc)
+	}
+
+	dst := &failingStorage{Store: memory.New(), failed: blobs[1].Digest}
+	err := uploadBlobs(ctx, src, dst, blobs, oras.CopyGraphOptions{Concurrency: 1})
+	if err == nil {
+		t.Fatal("uploadBlobs() error = nil, want error")
+	}
+	if !strings.Contains(err.Error(), "1 of 3 blobs failed") || !strings.Contains(err.Error(), "bar.txt") {
+		t.Errorf("uploadBlobs() error = %v, want the failed blob reported", err)
+	}
+	for _, blob := range []ocispec.Descriptor{blobs[0], blobs[2]} {
+		if exists, err := dst.Exists(ctx, blob); err != nil || !exists {
+			t.Errorf("blob %s is not uploaded after a failure, error = %v", blob.Annotations[ocispec.AnnotationTitle], err)
+		}
+	}
+
+	dst.failed = ""
+	if err := uploadBlobs(ctx, src, dst, blobs, oras.CopyGraphOptions{}); err != nil {
+		t.Error("uploadBlobs() error =", err)
+	}
+}
+
+func Test_pushOptions_parseMountFrom(t *testing.T) {
+	tests := []struct {
+		name      string
+		mountFrom []string
+		want      []string
+		wantErr   bool
+	}{
+		{name: "no repository", mountFrom: nil, want: nil},
+		{name: "same registry", mountFrom: []string{"localhost:5000/base", "localhost:5000/team/app"}, want: []string{"base", "team/app"}},
+		{name: "different registry", mountFrom: []string{"example.com/base"}, wantErr: true},
+		{name: "tagged reference", mountFrom: []string{"localhost:5000/base:v1"}, wantErr: true},
+		{name: "invalid reference", mountFrom: []string{"localhost:5000/Base"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &pushOptions{mountFrom: tt.mountFrom}
+			opts.RawReference = "localhost:5000/hello:v1"
+			err := opts.parseMountFrom()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMountFrom() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && strings.Join(opts.mountRepos, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("parseMountFrom() repositories = %v, want %v", opts.mountRepos, tt.want)
+			}
+		})
+	}
+
+	opts := &pushOptions{mountFrom: []string{"localhost:5000/base"}}
+	opts.Target.Type = option.TargetTypeOCILayout
+	if err := opts.parseMountFrom(); err == nil {
+		t.Error("parseMountFrom() error = nil for OCI image layout, want error")
+	}
+}
+
+func Test_runPush_dryRun(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	var out bytes.Buffer
+	opts := &pushOptions{
+		Format: option.Format{Type: option.FormatTypeText.Name},
+		dryRun: true,
+	}
+	opts.Printer = output.NewPrinter(&out, io.Discard, false)
+	opts.Target.Type = option.TargetTypeRemote
+	opts.RawReference = "localhost:1/hello:v1"
+	opts.Reference = "v1"
+	opts.PackVersion = oras.PackManifestVersion1_1
+	opts.artifactType = "application/vnd.test"
+
+	// the registry is unreachable, so any network I/O fails
+	if err := runPush(cmd, opts); err != nil {
+		t.Fatal("runPush() error =", err)
+	}
+	got := out.String()
+	for _, want := range []string{"Dry run, nothing is pushed to [registry] localhost:1/hello:v1", "ArtifactType: application/vnd.test", "Digest: sha256:"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("runPush() output = %q, want to contain %q", got, want)
+		}
+	}
+}
+
+func Test_pushPlatformConfig(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	platform := ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}
+	desc, err := pushPlatformConfig(ctx, store, oras.MediaTypeUnknownConfig, platform)
+	if err != nil {
+		t.Fatal("pushPlatformConfig() error =", err)
+	}
+	if desc.MediaType != oras.MediaTypeUnknownConfig {
+		t.Errorf("pushPlatformConfig() media type = %s, want %s", desc.MediaType, oras.MediaTypeUnknownConfig)
+	}
+	got, err := content.FetchAll(ctx, store, desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"architecture":"arm64","os":"linux","variant":"v8"}`; string(got) != want {
+		t.Errorf("pushPlatformConfig() config = %s, want %s", got, want)
+	}
+}
+
+func Test_runPush_dryRunJSON(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hi.txt")
+	if err := os.WriteFile(path, []byte("hi"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	var out bytes.Buffer
+	opts := &pushOptions{
+		Format: option.Format{Type: option.FormatTypeJSON.Name},
+		dryRun: true,
+	}
+	opts.Printer = output.NewPrinter(&out, io.Discard, false)
+	opts.Target.Type = option.TargetTypeRemote
+	opts.RawReference = "localhost:1/hello:v1"
+	opts.Reference = "v1"
+	opts.PackVersion = oras.PackManifestVersion1_1
+	opts.artifactType = "application/vnd.test"
+	opts.FileRefs = []string{path}
+
+	if err := runPush(cmd, opts); err != nil {
+		t.Fatal("runPush() error =", err)
+	}
+	var got struct {
+		ArtifactType string `json:"artifactType"`
+		Layers       []ocispec.Descriptor
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("runPush() output %q is not JSON: %v", out.String(), err)
+	}
+	if got.ArtifactType != "application/vnd.test" {
+		t.Errorf("runPush() artifact type = %q, want application/vnd.test", got.ArtifactType)
+	}
+	if len(got.Layers) != 1 || got.Layers[0].Digest != digest.FromString("hi") {
+		t.Errorf("runPush() layers = %v, want the layer of hi.txt", got.Layers)
+	}
+}
+
+func Test_pushOptions_layerFilter(t *testing.T) {
+	layer := func(name, mediaType string, dir bool) ocispec.Descriptor {
+		desc := ocispec.Descriptor{MediaType: mediaType, Annotations: map[string]string{ocispec.AnnotationTitle: name}}
+		if dir {
+			desc.Annotations[file.AnnotationUnpack] = "true"
+		}
+		return desc
+	}
+	patterns, err := compileExcludePatterns([]string{"*.tmp", "build/"})
+	if err != nil {
+		t.Fatal("compileExcludePatterns() error =", err)
+	}
+	opts := &pushOptions{excludedMediaTypes: []string{"application/vnd.me.tmp"}, excludePatterns: patterns}
+	keep := opts.layerFilter()
+	for _, tt := range []struct {
+		desc ocispec.Descriptor
+		want bool
+	}{
+		{layer("hi.txt", "text/plain", false), true},
+		{layer("hi.txt", "application/vnd.me.tmp", false), false},
+		{layer("out/a.tmp", "text/plain", false), false},
+		{layer("build", "text/plain", true), false},
+		{layer("build", "text/plain", false), true},
+	} {
+		if got := keep(tt.desc); got != tt.want {
+			t.Errorf("layerFilter()(%s) = %v, want %v", tt.desc.Annotations[ocispec.AnnotationTitle], got, tt.want)
+		}
+	}
+	if keep := (&pushOptions{}).layerFilter(); keep != nil {
+		t.Error("layerFilter() without filter flags should return nil")
+	}
+	if _, err := compileExcludePatterns([]string{"!keep.tmp"}); err == nil {
+		t.Error("compileExcludePatterns() with a negated pattern error = nil, want error")
+	}
+}
+
+func Test_pushOptions_parseSubject(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+		want    string
+		wantErr bool
+	}{
+		{name: "no subject", subject: "", want: ""},
+		{name: "tag", subject: "v0", want: "v0"},
+		{name: "full reference", subject: "localhost:5000/hello@sha256:9d16f5505246424aed7116cb21216704ba8c919997d0f1f37e154c11d509e1d2", want: "sha256:9d16f5505246424aed7116cb21216704ba8c919997d0f1f37e154c11d509e1d2"},
+		{name: "different repository", subject: "localhost:5000/base:v1", wantErr: true},
+		{name: "different registry", subject: "example.com/hello:v1", wantErr: true},
+		{name: "no tag or digest", subject: "localhost:5000/hello", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &pushOptions{subject: tt.subject}
+			opts.Target.Type = option.TargetTypeRemote
+			opts.RawReference = "localhost:5000/hello:v1"
+			err := opts.parseSubject()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSubject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && opts.subjectRef != tt.want {
+				t.Errorf("parseSubject() reference = %q, want %q", opts.subjectRef, tt.want)
+			}
+		})
+	}
+}
+
+func Test_runPush_fileOrder(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "docs"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.txt", "docs/b.txt", "docs/debug.log"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("a.txt", filepath.Join(dir, "latest")); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	cmd.SetIn(strings.NewReader("stdin"))
+	var out bytes.Buffer
+	opts := &pushOptions{
+		Format:           option.Format{Type: option.FormatTypeJSON.Name},
+		dryRun:           true,
+		preserveSymlinks: true,
+		stdinName:        "stdin.txt",
+	}
+	if opts.excludePatterns, err = compileExcludePatterns([]string{"*.log"}); err != nil {
+		t.Fatal(err)
+	}
+	opts.Printer = output.NewPrinter(&out, io.Discard, false)
+	opts.Target.Type = option.TargetTypeRemote
+	opts.RawReference = "localhost:1/hello:v1"
+	opts.Reference = "v1"
+	opts.PackVersion = oras.PackManifestVersion1_1
+	opts.artifactType = "application/vnd.test"
+	opts.FileRefs = []string{"latest", "a.txt", "-", "docs"}
+
+	if err := runPush(cmd, opts); err != nil {
+		t.Fatal("runPush() error =", err)
+	}
+	var got struct {
+		Layers []ocispec.Descriptor
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("runPush() output %q is not JSON: %v", out.String(), err)
+	}
+	var names []string
+	for _, layer := range got.Layers {
+		names = append(names, layer.Annotations[ocispec.AnnotationTitle])
+	}
+	if want := []string{"latest", "a.txt", "stdin.txt", "docs"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("runPush() layers = %v, want %v", names, want)
+	}
+}
+
+func Test_runPush_renamedCompressed(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "hi.txt"), []byte("hi"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	var out bytes.Buffer
+	opts := &pushOptions{
+		Format:             option.Format{Type: option.FormatTypeJSON.Name},
+		dryRun:             true,
+		onDuplicate:        onDuplicateRename,
+		compression:        compressionGzip,
+		preserveAttributes: true,
+		artifactType:       "application/vnd.test",
+	}
+	opts.Printer = output.NewPrinter(&out, io.Discard, false)
+	opts.Target.Type = option.TargetTypeRemote
+	opts.RawReference = "localhost:1/hello:v1"
+	opts.Reference = "v1"
+	opts.PackVersion = oras.PackManifestVersion1_1
+	opts.FileRefs = []string{"hi.txt", "./hi.txt"}
+
+	if err := runPush(cmd, opts); err != nil {
+		t.Fatal("runPush() error =", err)
+	}
+	var got struct {
+		Layers []ocispec.Descriptor
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("runPush() output %q is not JSON: %v", out.String(), err)
+	}
+	var names []string
+	for _, layer := range got.Layers {
+		names = append(names, layer.Annotations[ocispec.AnnotationTitle])
+		if want := digest.FromString("hi").String(); layer.Annotations[annotationUncompressedDigest] != want {
+			t.Errorf("uncompressed digest of %s = %s, want %s", layer.Annotations[ocispec.AnnotationTitle], layer.Annotations[annotationUncompressedDigest], want)
+		}
+	}
+	if want := []string{"hi.txt.gz", "hi-1.txt.gz"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("runPush() layers = %v, want %v", names, want)
+	}
+}
+
+func Test_runPush_compressedSplit(t *testing.T) {
+	dir := t.TempDir()
+	data := bytes.Repeat([]byte("0123456789"), 100)
+	if err := os.WriteFile(filepath.Join(dir, "data.bin"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	var out bytes.Buffer
+	opts := &pushOptions{
+		Format:       option.Format{Type: option.FormatTypeJSON.Name},
+		dryRun:       true,
+		compression:  compressionGzip,
+		splitSize:    8,
+		artifactType: "application/vnd.test",
+	}
+	opts.Printer = output.NewPrinter(&out, io.Discard, false)
+	opts.Target.Type = option.TargetTypeRemote
+	opts.RawReference = "localhost:1/hello:v1"
+	opts.Reference = "v1"
+	opts.PackVersion = oras.PackManifestVersion1_1
+	opts.FileRefs = []string{"data.bin"}
+
+	if err := runPush(cmd, opts); err != nil {
+		t.Fatal("runPush() error =", err)
+	}
+	var got struct {
+		Layers []ocispec.Descriptor
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("runPush() output %q is not JSON: %v", out.String(), err)
+	}
+	if len(got.Layers) < 2 {
+		t.Fatalf("runPush() layers = %v, want the compressed file split into chunks", got.Layers)
+	}
+	for i, layer := range got.Layers {
+		if want := chunkName("data.bin.gz", i); layer.Annotations[ocispec.AnnotationTitle] != want {
+			t.Errorf("layer %d name = %s, want %s", i, layer.Annotations[ocispec.AnnotationTitle], want)
+		}
+		if want := digest.FromBytes(data).String(); layer.Annotations[annotationUncompressedDigest] != want {
+			t.Errorf("layer %d uncompressed digest = %s, want %s", i, layer.Annotations[annotationUncompressedDigest], want)
+		}
+	}
+}
+
+func Test_pushCmd_requestsPerSecond(t *testing.T) {
+	for _, value := range []string{"-1", "1e-10", "NaN"} {
+		t.Run(value, func(t *testing.T) {
+			cmd := pushCmd()
+			cmd.SetArgs([]string{"--requests-per-second", value, "localhost:5000/test:v1", "hi.txt"})
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			err := cmd.ExecuteContext(context.Background())
+			if err == nil || !strings.Contains(err.Error(), "--requests-per-second") {
+				t.Errorf("push --requests-per-second %s error = %v, want an invalid value error", value, err)
+			}
+		})
+	}
+}
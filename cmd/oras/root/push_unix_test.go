@@ -0,0 +1,80 @@
+//go:build freebsd || linux || netbsd || openbsd || solaris
+
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func Test_runManifestHook(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	packOpts := oras.PackManifestOptions{ManifestAnnotations: map[string]string{"foo": "bar"}}
+	root, err := oras.PackManifest(ctx, store, oras.PackManifestVersion1_1, "application/vnd.test", packOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	writeHook := func(script string) string {
+		path := filepath.Join(dir, "hook.sh")
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0700); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// audit only
+	hook := writeHook(`[ "$ORAS_MANIFEST_DIGEST" = "` + root.Digest.String() + `" ] && cat > /dev/null`)
+	got, err := runManifestHook(ctx, hook, store, root)
+	if err != nil {
+		t.Fatal("runManifestHook() error =", err)
+	}
+	if !content.Equal(got, root) {
+		t.Errorf("runManifestHook() = %v, want %v", got, root)
+	}
+
+	// rewrite
+	hook = writeHook(`sed 's/"foo":"bar"/"foo":"baz"/'`)
+	got, err = runManifestHook(ctx, hook, store, root)
+	if err != nil {
+		t.Fatal("runManifestHook() error =", err)
+	}
+	if got.Digest == root.Digest {
+		t.Error("runManifestHook() returned the original manifest, want rewritten")
+	}
+	if got.Annotations["foo"] != "baz" || got.ArtifactType != "application/vnd.test" {
+		t.Errorf("runManifestHook() = %v, want annotation foo=baz and artifact type application/vnd.test", got)
+	}
+	if exists, err := store.Exists(ctx, got); err != nil || !exists {
+		t.Errorf("rewritten manifest is not pushed, exists = %v, error = %v", exists, err)
+	}
+
+	// failures
+	for _, script := range []string{"exit 1", "echo invalid", `echo '{"mediaType":"application/vnd.oci.image.index.v1+json"}'`} {
+		if _, err := runManifestHook(ctx, writeHook(script), store, root); err == nil {
+			t.Errorf("runManifestHook() with hook %q error = nil, want error", script)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content/file"
+)
+
+// Annotations of a layer holding a chunk of a file split into multiple
+// layers.
+const (
+	// annotationChunkOf is the name of the file the chunk belongs to.
+	annotationChunkOf = "land.oras.chunk.of"
+	// annotationChunkIndex is the zero-based position of the chunk in the
+	// file.
+	annotationChunkIndex = "land.oras.chunk.index"
+	// annotationChunkDigest is the digest of the whole file.
+	annotationChunkDigest = "land.oras.chunk.digest"
+)
+
+// chunkName returns the name of the index-th chunk of the file name.
+func chunkName(name string, index int) string {
+	return fmt.Sprintf("%s.part%04d", name, index)
+}
+
+// splitLayers splits the file layers larger than chunkSize into fixed-size
+// chunks, each added to store as its own layer. The chunks are written into
+// tempDir, named after the position of the layer. Directories are never
+// split.
+func splitLayers(ctx context.Context, store *file.Store, sources fileSources, layers []ocispec.Descriptor, chunkSize int64, tempDir string) ([]ocispec.Descriptor, error) {
+	var ret []ocispec.Descriptor
+	for i, layer := range layers {
+		name := layer.Annotations[ocispec.AnnotationTitle]
+		if _, ok := layer.Annotations[annotationSymlink]; ok || layer.Size <= chunkSize || name == "" || layer.Annotations[file.AnnotationUnpack] == "true" {
+			ret = append(ret, layer)
+			continue
+		}
+		chunks, err := splitFile(ctx, store, sources, layer, chunkSize, filepath.Join(tempDir, strconv.Itoa(i)))
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, chunks...)
+	}
+	return ret, nil
+}
+
+// splitFile splits the source file of layer into chunks of chunkSize, written
+// to prefix followed by the chunk index.
+func splitFile(ctx context.Context, store *file.Store, sources fileSources, layer ocispec.Descriptor, chunkSize int64, prefix string) ([]ocispec.Descriptor, error) {
+	name := layer.Annotations[ocispec.AnnotationTitle]
+	src, err := sources.path(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var chunks []ocispec.Descriptor
+	for index := 0; int64(index)*chunkSize < layer.Size; index++ {
+		path := fmt.Sprintf("%s.part%04d", prefix, index)
+		if err := writeChunk(path, io.LimitReader(f, chunkSize)); err != nil {
+			return nil, err
+		}
+		chunk, err := addFile(ctx, store, sources, chunkName(name, index), layer.MediaType, path)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range layer.Annotations {
+			if k != ocispec.AnnotationTitle {
+				chunk.Annotations[k] = v
+			}
+		}
+		chunk.Annotations[annotationChunkOf] = name
+		chunk.Annotations[annotationChunkIndex] = strconv.Itoa(index)
+		chunk.Annotations[annotationChunkDigest] = layer.Digest.String()
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+func writeChunk(path string, r io.Reader) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// joinChunks reassembles the chunks pulled into outputDir back into the
+// original files, ordered by their index, and removes the chunk files. The
+// layers are returned with the chunks of each file replaced by the layer of
+// the reassembled file. Layers which are not chunks are kept as is.
+func joinChunks(outputDir string, layers []ocispec.Descriptor, keepOldFiles bool) ([]ocispec.Descriptor, error) {
+	files := make(map[string]map[int]ocispec.Descriptor)
+	for _, layer := range layers {
+		name, ok := layer.Annotations[annotationChunkOf]
+		if !ok {
+			continue
+		}
+		index, err := strconv.Atoi(layer.Annotations[annotationChunkIndex])
+		if err != nil || index < 0 {
+			return nil, fmt.Errorf("invalid chunk index %q of %s", layer.Annotations[annotationChunkIndex], name)
+		}
+		// the chunk name has been validated when pulled, so the file is
+		// written next to it
+		if layer.Annotations[ocispec.AnnotationTitle] != chunkName(name, index) {
+			return nil, fmt.Errorf("chunk %q does not match the file %s", layer.Annotations[ocispec.AnnotationTitle], name)
+		}
+		if files[name] == nil {
+			files[name] = make(map[int]ocispec.Descriptor)
+		}
+		files[name][index] = layer
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := joinFile(outputDir, name, files[name], keepOldFiles); err != nil {
+			return nil, err
+		}
+	}
+
+	ret := make([]ocispec.Descriptor, 0, len(layers))
+	for _, layer := range layers {
+		name, ok := layer.Annotations[annotationChunkOf]
+		if !ok {
+			ret = append(ret, layer)
+			continue
+		}
+		if chunks, ok := files[name]; ok {
+			ret = append(ret, joinedLayer(name, chunks))
+			delete(files, name)
+		}
+	}
+	return ret, nil
+}
+
+// joinedLayer returns the layer of the file name reassembled from chunks,
+// annotated as its first chunk apart from the chunk annotations.
+func joinedLayer(name string, chunks map[int]ocispec.Descriptor) ocispec.Descriptor {
+	first := chunks[0]
+	layer := ocispec.Descriptor{
+		MediaType:   first.MediaType,
+		Digest:      digest.Digest(first.Annotations[annotationChunkDigest]),
+		Annotations: make(map[string]string),
+	}
+	for _, chunk := range chunks {
+		layer.Size += chunk.Size
+	}
+	for k, v := range first.Annotations {
+		switch k {
+		case annotationChunkOf, annotationChunkIndex, annotationChunkDigest:
+		default:
+			layer.Annotations[k] = v
+		}
+	}
+	layer.Annotations[ocispec.AnnotationTitle] = name
+	return layer
+}
+
+// joinFile concatenates the chunks into the file name.
+func joinFile(outputDir, name string, chunks map[int]ocispec.Descriptor, keepOldFiles bool) error {
+	resolve := func(name string) string {
+		if filepath.IsAbs(name) {
+			return name
+		}
+		return filepath.Join(outputDir, name)
+	}
+	expected, err := digest.Parse(chunks[0].Annotations[annotationChunkDigest])
+	if err != nil {
+		return fmt.Errorf("invalid digest of the chunked file %s: %w", name, err)
+	}
+	path := resolve(name)
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if keepOldFiles {
+		flag |= os.O_EXCL
+	}
+	f, err := os.OpenFile(path, flag, 0666)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	verifier := expected.Verifier()
+	w := io.MultiWriter(f, verifier)
+	for index := 0; index < len(chunks); index++ {
+		chunk, ok := chunks[index]
+		if !ok {
+			return fmt.Errorf("chunk %d of %s is missing", index, name)
+		}
+		if err := appendFile(w, resolve(chunk.Annotations[ocispec.AnnotationTitle])); err != nil {
+			return err
+		}
+	}
+	if !verifier.Verified() {
+		return fmt.Errorf("failed to verify the reassembled file %s: mismatched digest", name)
+	}
+	for _, chunk := range chunks {
+		if err := os.Remove(resolve(chunk.Annotations[ocispec.AnnotationTitle])); err != nil {
+			return err
+		}
+	}
+	return f.Close()
+}
+
+func appendFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
@@ -0,0 +1,217 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/file"
+)
+
+func Test_splitLayers_joinChunks(t *testing.T) {
+	ctx := context.Background()
+	srcDir := t.TempDir()
+	data := []byte("0123456789abcdefghij")
+	if err := os.WriteFile(filepath.Join(srcDir, "large.bin"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "small.bin"), data[:4], 0600); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(srcDir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+
+	store, err := file.New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	sources := make(fileSources)
+	var layers []ocispec.Descriptor
+	for _, name := range []string{"large.bin", "small.bin"} {
+		desc, err := addFile(ctx, store, sources, name, "", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		layers = append(layers, desc)
+	}
+
+	got, err := splitLayers(ctx, store, sources, layers, 8, t.TempDir())
+	if err != nil {
+		t.Fatal("splitLayers() error =", err)
+	}
+	wantNames := []string{"large.bin.part0000", "large.bin.part0001", "large.bin.part0002", "small.bin"}
+	if len(got) != len(wantNames) {
+		t.Fatalf("splitLayers() returned %d layers, want %d", len(got), len(wantNames))
+	}
+	for i, layer := range got {
+		if name := layer.Annotations[ocispec.AnnotationTitle]; name != wantNames[i] {
+			t.Errorf("layer %d name = %s, want %s", i, name, wantNames[i])
+		}
+	}
+	if size := got[2].Size; size != 4 {
+		t.Errorf("last chunk size = %d, want 4", size)
+	}
+
+	// simulate pulling the chunks
+	outputDir := t.TempDir()
+	for _, layer := range got[:3] {
+		rc, err := store.Fetch(ctx, layer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		_, err = buf.ReadFrom(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(outputDir, layer.Annotations[ocispec.AnnotationTitle]), buf.Bytes(), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	joinedLayers, err := joinChunks(outputDir, got, false)
+	if err != nil {
+		t.Fatal("joinChunks() error =", err)
+	}
+	if len(joinedLayers) != 2 || !content.Equal(joinedLayers[0], layers[0]) || joinedLayers[0].Annotations[ocispec.AnnotationTitle] != "large.bin" || !content.Equal(joinedLayers[1], layers[1]) {
+		t.Errorf("joinChunks() = %v, want the layers of large.bin and small.bin", joinedLayers)
+	}
+	joined, err := os.ReadFile(filepath.Join(outputDir, "large.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(joined, data) {
+		t.Errorf("joined file = %q, want %q", joined, data)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "large.bin.part0000")); !os.IsNotExist(err) {
+		t.Errorf("chunk file is not removed, stat error = %v", err)
+	}
+}
+
+func Test_splitLayers_sameBaseName(t *testing.T) {
+	ctx := context.Background()
+	srcDir := t.TempDir()
+	files := map[string][]byte{
+		"d1/a.bin": []byte("0123456789"),
+		"d2/a.bin": []byte("abcdefghij"),
+	}
+	for name, data := range files {
+		path := filepath.Join(srcDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(srcDir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+
+	store, err := file.New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	sources := make(fileSources)
+	var layers []ocispec.Descriptor
+	for _, name := range []string{"d1/a.bin", "d2/a.bin"} {
+		desc, err := addFile(ctx, store, sources, name, "", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		layers = append(layers, desc)
+	}
+
+	got, err := splitLayers(ctx, store, sources, layers, 4, t.TempDir())
+	if err != nil {
+		t.Fatal("splitLayers() error =", err)
+	}
+	if len(got) != 6 {
+		t.Fatalf("splitLayers() returned %d layers, want 6", len(got))
+	}
+	for _, chunk := range got {
+		name := chunk.Annotations[annotationChunkOf]
+		index, err := strconv.Atoi(chunk.Annotations[annotationChunkIndex])
+		if err != nil {
+			t.Fatal(err)
+		}
+		rc, err := store.Fetch(ctx, chunk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := files[name][index*4 : min(index*4+4, len(files[name]))]
+		if !bytes.Equal(content, want) {
+			t.Errorf("chunk %s = %q, want %q", chunk.Annotations[ocispec.AnnotationTitle], content, want)
+		}
+	}
+}
+
+func Test_joinChunks_invalid(t *testing.T) {
+	chunk := func(title, index string) ocispec.Descriptor {
+		return ocispec.Descriptor{Annotations: map[string]string{
+			ocispec.AnnotationTitle: title,
+			annotationChunkOf:       "a.bin",
+			annotationChunkIndex:    index,
+			annotationChunkDigest:   digest.FromString("a").String(),
+		}}
+	}
+	tests := []struct {
+		name   string
+		layers []ocispec.Descriptor
+	}{
+		{name: "invalid index", layers: []ocispec.Descriptor{chunk("a.bin.part0000", "x")}},
+		{name: "mismatched name", layers: []ocispec.Descriptor{chunk("../b.bin", "0")}},
+		{name: "missing chunk", layers: []ocispec.Descriptor{chunk("a.bin.part0001", "1")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := joinChunks(t.TempDir(), tt.layers, false); err == nil {
+				t.Error("joinChunks() error = nil, want error")
+			}
+		})
+	}
+}
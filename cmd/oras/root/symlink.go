@@ -0,0 +1,108 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras/cmd/oras/internal/display/status"
+	"oras.land/oras/cmd/oras/internal/fileref"
+)
+
+// annotationSymlink is the target of a pushed symbolic link.
+const annotationSymlink = "land.oras.file.symlink"
+
+// loadSymlink adds the file of fileRef to store as a layer holding the target
+// written at path, instead of the file it points to, if it is a symbolic
+// link. ok is false if it is not. Symbolic links in directories are kept by
+// the directory tarballs.
+func loadSymlink(ctx context.Context, store *file.Store, sources fileSources, annotations map[string]map[string]string, fileRef string, path string, displayStatus status.PushHandler) (desc ocispec.Descriptor, ok bool, err error) {
+	filename, mediaType, err := fileref.Parse(fileRef, "")
+	if err != nil {
+		return ocispec.Descriptor{}, false, err
+	}
+	info, err := os.Lstat(filename)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return ocispec.Descriptor{}, false, nil
+	}
+	target, err := os.Readlink(filename)
+	if err != nil {
+		return ocispec.Descriptor{}, false, err
+	}
+
+	name := filepath.Clean(filename)
+	if !filepath.IsAbs(name) {
+		name = filepath.ToSlash(name)
+	}
+	if err := displayStatus.OnFileLoading(name); err != nil {
+		return ocispec.Descriptor{}, false, err
+	}
+	if err := os.WriteFile(path, []byte(target), 0600); err != nil {
+		return ocispec.Descriptor{}, false, err
+	}
+	link, err := addFile(ctx, store, sources, name, mediaType, path)
+	if err != nil {
+		return ocispec.Descriptor{}, false, err
+	}
+	for k, v := range annotations[filename] {
+		link.Annotations[k] = v
+	}
+	link.Annotations[annotationSymlink] = target
+	return link, true, nil
+}
+
+// restoreSymlinks replaces the files of the symbolic link layers pulled into
+// outputDir with the links. Links pointing out of outputDir are rejected
+// unless allowPathTraversal is set.
+func restoreSymlinks(outputDir string, layers []ocispec.Descriptor, allowPathTraversal bool) error {
+	for _, layer := range layers {
+		target, ok := layer.Annotations[annotationSymlink]
+		name := layer.Annotations[ocispec.AnnotationTitle]
+		if !ok || name == "" {
+			continue
+		}
+		path := name
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(outputDir, name)
+		}
+		if !allowPathTraversal {
+			resolved := target
+			if !filepath.IsAbs(resolved) {
+				resolved = filepath.Join(filepath.Dir(path), target)
+			}
+			rel, err := filepath.Rel(outputDir, resolved)
+			if err != nil || !filepath.IsLocal(rel) {
+				return fmt.Errorf("symbolic link %s to %s: %w", name, target, file.ErrPathTraversalDisallowed)
+			}
+		}
+		if existing, err := os.Readlink(path); err == nil && existing == target {
+			// already restored
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		if err := os.Symlink(target, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
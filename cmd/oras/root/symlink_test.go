@@ -0,0 +1,113 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package root
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras/cmd/oras/internal/display/status"
+	"oras.land/oras/cmd/oras/internal/output"
+)
+
+func Test_loadSymlink(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	target := filepath.Join(dir, "app-1.2.bin")
+	if err := os.WriteFile(target, []byte("binary"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	linkPath := filepath.Join(dir, "latest")
+	if err := os.Symlink("app-1.2.bin", linkPath); err != nil {
+		t.Fatal(err)
+	}
+	store, err := file.New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	printer := output.NewPrinter(io.Discard, io.Discard, false)
+	if _, ok, err := loadSymlink(ctx, store, nil, nil, target, filepath.Join(t.TempDir(), "0.link"), status.NewTextPushHandler(printer)); err != nil || ok {
+		t.Fatalf("loadSymlink() = %v, %v, want the regular file not loaded", ok, err)
+	}
+	link, ok, err := loadSymlink(ctx, store, nil, nil, linkPath, filepath.Join(t.TempDir(), "1.link"), status.NewTextPushHandler(printer))
+	if err != nil {
+		t.Fatal("loadSymlink() error =", err)
+	}
+	if !ok || link.Annotations[annotationSymlink] != "app-1.2.bin" {
+		t.Fatalf("loadSymlink() = %v, want a link to app-1.2.bin", link)
+	}
+	got, err := content.FetchAll(ctx, store, link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "app-1.2.bin" {
+		t.Errorf("link content = %q, want the target %q", got, "app-1.2.bin")
+	}
+}
+
+func Test_restoreSymlinks(t *testing.T) {
+	layer := func(name, target string) ocispec.Descriptor {
+		return ocispec.Descriptor{Annotations: map[string]string{
+			ocispec.AnnotationTitle: name,
+			annotationSymlink:       target,
+		}}
+	}
+	tests := []struct {
+		name               string
+		target             string
+		allowPathTraversal bool
+		wantErr            bool
+	}{
+		{name: "relative target", target: "app-1.2.bin"},
+		{name: "target out of the output directory", target: "../app-1.2.bin", wantErr: true},
+		{name: "allowed target out of the output directory", target: "../app-1.2.bin", allowPathTraversal: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			path := filepath.Join(outputDir, "latest")
+			if err := os.WriteFile(path, []byte(tt.target), 0600); err != nil {
+				t.Fatal(err)
+			}
+			err := restoreSymlinks(outputDir, []ocispec.Descriptor{layer("latest", tt.target)}, tt.allowPathTraversal)
+			if tt.wantErr {
+				if !errors.Is(err, file.ErrPathTraversalDisallowed) {
+					t.Fatalf("restoreSymlinks() error = %v, want %v", err, file.ErrPathTraversalDisallowed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("restoreSymlinks() error =", err)
+			}
+			got, err := os.Readlink(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.target {
+				t.Errorf("restored link target = %q, want %q", got, tt.target)
+			}
+		})
+	}
+}
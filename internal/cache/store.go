@@ -0,0 +1,113 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content/oci"
+)
+
+// Store is a cache storing content by digest in an OCI image layout. The
+// modification time of a cached blob is its last access time, so that the
+// least recently used blobs are evicted first by GC.
+type Store struct {
+	*oci.Store
+	root     string
+	maxBytes int64
+}
+
+// NewStore returns a cache stored under root and limited to maxBytes by GC.
+// The size is unlimited if maxBytes is not positive.
+func NewStore(root string, maxBytes int64) (*Store, error) {
+	store, err := oci.New(root)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{
+		Store:    store,
+		root:     root,
+		maxBytes: maxBytes,
+	}, nil
+}
+
+// Fetch fetches the content identified by the descriptor and records the
+// access.
+func (s *Store) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	rc, err := s.Store.Fetch(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if target.Digest.Validate() == nil {
+		// the access time is best-effort
+		now := time.Now()
+		_ = os.Chtimes(s.blobPath(target), now, now)
+	}
+	return rc, nil
+}
+
+// GC removes the least recently used blobs until the size of the cached
+// blobs is under the limit of the store.
+func (s *Store) GC() error {
+	if s.maxBytes <= 0 {
+		return nil
+	}
+	type blob struct {
+		path     string
+		size     int64
+		accessed time.Time
+	}
+	var blobs []blob
+	var total int64
+	if err := filepath.WalkDir(filepath.Join(s.root, ocispec.ImageBlobsDir), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		blobs = append(blobs, blob{path: path, size: info.Size(), accessed: info.ModTime()})
+		total += info.Size()
+		return nil
+	}); err != nil {
+		return err
+	}
+	sort.Slice(blobs, func(i, j int) bool {
+		return blobs[i].accessed.Before(blobs[j].accessed)
+	})
+	for _, b := range blobs {
+		if total <= s.maxBytes {
+			break
+		}
+		if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= b.size
+	}
+	return nil
+}
+
+func (s *Store) blobPath(desc ocispec.Descriptor) string {
+	return filepath.Join(s.root, ocispec.ImageBlobsDir, desc.Digest.Algorithm().String(), desc.Digest.Encoded())
+}
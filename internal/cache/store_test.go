@@ -0,0 +1,66 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+func TestStore_GC(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewStore(t.TempDir(), 12)
+	if err != nil {
+		t.Fatal("NewStore() error =", err)
+	}
+	descs := make(map[string]ocispec.Descriptor)
+	past := time.Now().Add(-time.Hour)
+	for i, blob := range []string{"fetched", "older", "newer"} {
+		desc := content.NewDescriptorFromBytes("test", []byte(blob))
+		if err := s.Push(ctx, desc, bytes.NewReader([]byte(blob))); err != nil {
+			t.Fatal(err)
+		}
+		accessed := past.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(s.blobPath(desc), accessed, accessed); err != nil {
+			t.Fatal(err)
+		}
+		descs[blob] = desc
+	}
+	// the blobs are 17 bytes in total, fetching the oldest blob makes it the
+	// most recently used
+	if _, err := content.FetchAll(ctx, s, descs["fetched"]); err != nil {
+		t.Fatal("Store.Fetch() error =", err)
+	}
+
+	if err := s.GC(); err != nil {
+		t.Fatal("Store.GC() error =", err)
+	}
+	for blob, want := range map[string]bool{"older": false, "newer": true, "fetched": true} {
+		exists, err := s.Exists(ctx, descs[blob])
+		if err != nil {
+			t.Fatal("Store.Exists() error =", err)
+		}
+		if exists != want {
+			t.Errorf("Store.Exists(%q) after GC = %v, want %v", blob, exists, want)
+		}
+	}
+}
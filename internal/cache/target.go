@@ -17,6 +17,8 @@ package cache
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"sync"
 
@@ -26,6 +28,11 @@ import (
 	"oras.land/oras-go/v2/registry"
 )
 
+// ErrCorrupted is returned when the content read from the cache does not
+// match its descriptor. The corrupted content is removed from the cache so
+// that it is fetched again from the source next time.
+var ErrCorrupted = errors.New("corrupted cache content")
+
 type closer func() error
 
 func (fn closer) Close() error {
@@ -36,6 +43,7 @@ func (fn closer) Close() error {
 type target struct {
 	oras.ReadOnlyTarget
 	cache content.Storage
+	hits  sync.Map // map[digest.Digest]bool
 }
 
 // New generates a new target storage with caching.
@@ -53,12 +61,29 @@ func New(source oras.ReadOnlyTarget, cache content.Storage) oras.ReadOnlyTarget
 	return t
 }
 
+// Hit returns true if the content identified by desc has been fetched from
+// the cache of the target storage, which is created by New.
+func Hit(storage oras.ReadOnlyTarget, desc ocispec.Descriptor) bool {
+	var t *target
+	switch s := storage.(type) {
+	case *target:
+		t = s
+	case *referenceTarget:
+		t = s.target
+	default:
+		return false
+	}
+	_, ok := t.hits.Load(desc.Digest)
+	return ok
+}
+
 // Fetch fetches the content identified by the descriptor.
 func (t *target) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
 	rc, err := t.cache.Fetch(ctx, target)
 	if err == nil {
 		// Fetch from cache
-		return rc, nil
+		t.hits.Store(target.Digest, true)
+		return t.verifyReadCloser(ctx, rc, target), nil
 	}
 
 	if rc, err = t.ReadOnlyTarget.Fetch(ctx, target); err != nil {
@@ -69,6 +94,50 @@ func (t *target) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadC
 	return t.cacheReadCloser(ctx, rc, target), nil
 }
 
+// verifyReadCloser verifies the content read from the cache against target,
+// removing it from the cache if corrupted.
+func (t *target) verifyReadCloser(ctx context.Context, rc io.ReadCloser, target ocispec.Descriptor) io.ReadCloser {
+	vr := content.NewVerifyReader(rc, target)
+	var size int64
+	read := func(p []byte) (int, error) {
+		n, err := vr.Read(p)
+		size += int64(n)
+		// verify as soon as the content is entirely read, since the readers
+		// may not read until EOF
+		if err == io.EOF || (err == nil && size == target.Size) {
+			if verifyErr := vr.Verify(); verifyErr != nil {
+				err = verifyErr
+			}
+		}
+		if err == nil || err == io.EOF || !isCorrupted(err) {
+			return n, err
+		}
+		if deleter, ok := t.cache.(content.Deleter); ok {
+			_ = deleter.Delete(ctx, target)
+		}
+		return n, fmt.Errorf("%w %s: %v", ErrCorrupted, target.Digest, err)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: readerFunc(read),
+		Closer: rc,
+	}
+}
+
+// isCorrupted returns true if err is returned for content not matching its
+// descriptor.
+func isCorrupted(err error) bool {
+	return errors.Is(err, content.ErrMismatchedDigest) || errors.Is(err, content.ErrTrailingData) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+type readerFunc func(p []byte) (int, error)
+
+func (fn readerFunc) Read(p []byte) (int, error) {
+	return fn(p)
+}
+
 func (t *target) cacheReadCloser(ctx context.Context, rc io.ReadCloser, target ocispec.Descriptor) io.ReadCloser {
 	pr, pw := io.Pipe()
 	var wg sync.WaitGroup
@@ -145,6 +214,7 @@ func (t *referenceTarget) FetchReference(ctx context.Context, reference string)
 		}
 
 		// no need to do tee'd push
+		t.hits.Store(target.Digest, true)
 		return target, rc, nil
 	}
 
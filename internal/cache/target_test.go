@@ -19,11 +19,13 @@ import (
 	"bytes"
 	"context"
 	_ "crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"reflect"
 	"strconv"
 	"sync/atomic"
@@ -270,3 +272,77 @@ func TestProxy_fetchReference(t *testing.T) {
 		t.Errorf("unexpected number of successful requests: %d, want %d", successCount, wantSuccessCount)
 	}
 }
+
+func TestHit(t *testing.T) {
+	blob := []byte("hello world")
+	desc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}
+	ctx := context.Background()
+	source := memory.New()
+	if err := source.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal(err)
+	}
+	target := New(source, memory.New())
+
+	// first fetch from the source
+	if _, err := content.FetchAll(ctx, target, desc); err != nil {
+		t.Fatal("Target.Fetch() error =", err)
+	}
+	if Hit(target, desc) {
+		t.Error("Hit() = true for content fetched from the source, want false")
+	}
+
+	// repeated fetch from the cache
+	if _, err := content.FetchAll(ctx, target, desc); err != nil {
+		t.Fatal("Target.Fetch() error =", err)
+	}
+	if !Hit(target, desc) {
+		t.Error("Hit() = false for content fetched from the cache, want true")
+	}
+	if Hit(source, desc) {
+		t.Error("Hit() = true for a target without cache, want false")
+	}
+}
+
+func TestProxy_fetchCorruptedCache(t *testing.T) {
+	blob := []byte("hello world")
+	desc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}
+	ctx := context.Background()
+	source := memory.New()
+	if err := source.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewStore(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(store.blobPath(desc), []byte("hello earth"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	target := New(source, store)
+
+	if _, err := content.FetchAll(ctx, target, desc); !errors.Is(err, ErrCorrupted) {
+		t.Fatalf("Target.Fetch() error = %v, want %v", err, ErrCorrupted)
+	}
+	if exists, err := store.Exists(ctx, desc); err != nil || exists {
+		t.Fatalf("corrupted content exists in the cache = %v, error = %v, want removed", exists, err)
+	}
+	// fetched again from the source
+	got, err := content.FetchAll(ctx, target, desc)
+	if err != nil {
+		t.Fatal("Target.Fetch() error =", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("Target.Fetch() = %v, want %v", got, blob)
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package contentutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+)
+
+// timedOut reports whether an attempt bounded by attemptCtx failed because it
+// exceeded its own deadline rather than because ctx is done.
+func timedOut(ctx, attemptCtx context.Context) bool {
+	return ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded)
+}
+
+type timeoutTarget struct {
+	oras.GraphTarget
+	src     content.Fetcher
+	timeout time.Duration
+	retries int
+}
+
+// NewTimeoutTarget returns a target cancelling the push of a blob to dst which
+// does not complete within timeout. A cancelled push is retried up to retries
+// times with the content fetched again from src.
+func NewTimeoutTarget(dst oras.GraphTarget, src content.Fetcher, timeout time.Duration, retries int) oras.GraphTarget {
+	return &timeoutTarget{
+		GraphTarget: dst,
+		src:         src,
+		timeout:     timeout,
+		retries:     retries,
+	}
+}
+
+// Push pushes the content to the base target, retrying on timeout.
+func (t *timeoutTarget) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	for attempt := 0; ; attempt++ {
+		pushCtx, cancel := context.WithTimeout(ctx, t.timeout)
+		err := t.GraphTarget.Push(pushCtx, expected, r)
+		timeout := timedOut(ctx, pushCtx)
+		cancel()
+		if err == nil || !timeout {
+			return err
+		}
+		if attempt == t.retries {
+			return fmt.Errorf("failed to push %s within %v after %d attempts: %w", expected.Digest, t.timeout, attempt+1, err)
+		}
+		rc, err := t.src.Fetch(ctx, expected)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		r = rc
+	}
+}
+
+// Mount mounts a blob from a specified repository. This method is invoked only
+// by the `*remote.Repository` target.
+func (t *timeoutTarget) Mount(ctx context.Context, desc ocispec.Descriptor, fromRepo string, getContent func() (io.ReadCloser, error)) error {
+	mounter := t.GraphTarget.(registry.Mounter)
+	return mounter.Mount(ctx, desc, fromRepo, getContent)
+}
+
+type timeoutReadOnlyTarget struct {
+	oras.ReadOnlyTarget
+	timeout time.Duration
+	retries int
+}
+
+// NewTimeoutReadOnlyTarget returns a target cancelling the fetch of a blob from
+// src which is not read completely within timeout. A cancelled fetch is
+// retried up to retries times, resuming from the bytes already read.
+func NewTimeoutReadOnlyTarget(src oras.ReadOnlyTarget, timeout time.Duration, retries int) oras.ReadOnlyTarget {
+	return &timeoutReadOnlyTarget{
+		ReadOnlyTarget: src,
+		timeout:        timeout,
+		retries:        retries,
+	}
+}
+
+// Fetch fetches the content from the base target, retrying on timeout.
+func (t *timeoutReadOnlyTarget) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	r := &timeoutReader{
+		ctx:    ctx,
+		target: t,
+		desc:   target,
+	}
+	if err := r.fetch(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// timeoutReader reads a blob, fetching it again if the current attempt times
+// out.
+type timeoutReader struct {
+	ctx     context.Context
+	target  *timeoutReadOnlyTarget
+	desc    ocispec.Descriptor
+	attempt int
+	offset  int64
+
+	fetchCtx context.Context
+	cancel   context.CancelFunc
+	rc       io.ReadCloser
+}
+
+// fetch starts a new attempt, skipping the bytes already read.
+func (r *timeoutReader) fetch() error {
+	for {
+		r.fetchCtx, r.cancel = context.WithTimeout(r.ctx, r.target.timeout)
+		rc, err := r.target.ReadOnlyTarget.Fetch(r.fetchCtx, r.desc)
+		if err == nil {
+			if _, err = io.CopyN(io.Discard, rc, r.offset); err == nil {
+				r.rc = rc
+				return nil
+			}
+			rc.Close()
+		}
+		if err = r.retry(err); err != nil {
+			return err
+		}
+	}
+}
+
+// retry cancels the current attempt and returns nil if err is caused by the
+// timeout of the attempt and another attempt is allowed.
+func (r *timeoutReader) retry(err error) error {
+	timeout := timedOut(r.ctx, r.fetchCtx)
+	r.cancel()
+	if !timeout {
+		return err
+	}
+	if r.attempt == r.target.retries {
+		return fmt.Errorf("failed to fetch %s within %v after %d attempts: %w", r.desc.Digest, r.target.timeout, r.attempt+1, err)
+	}
+	r.attempt++
+	return nil
+}
+
+// Read reads from the current attempt, retrying on timeout.
+func (r *timeoutReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.rc.Read(p)
+		r.offset += int64(n)
+		if err == nil || err == io.EOF {
+			return n, err
+		}
+		r.rc.Close()
+		if err = r.retry(err); err != nil {
+			return n, err
+		}
+		if err = r.fetch(); err != nil {
+			return n, err
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// Close closes the current attempt.
+func (r *timeoutReader) Close() error {
+	defer r.cancel()
+	return r.rc.Close()
+}
@@ -0,0 +1,113 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package contentutil
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+// stuckTarget is a memory target whose first stuck pushes and fetches hang
+// until their context is done.
+type stuckTarget struct {
+	*memory.Store
+	stuck int32
+	calls atomic.Int32
+}
+
+func (t *stuckTarget) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	if t.calls.Add(1) <= t.stuck {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return t.Store.Push(ctx, expected, r)
+}
+
+func (t *stuckTarget) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	rc, err := t.Store.Fetch(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if t.calls.Add(1) <= t.stuck {
+		// serve the first byte, then hang
+		return io.NopCloser(io.MultiReader(io.LimitReader(rc, 1), &ctxReader{ctx})), nil
+	}
+	return rc, nil
+}
+
+type ctxReader struct {
+	ctx context.Context
+}
+
+func (r *ctxReader) Read([]byte) (int, error) {
+	<-r.ctx.Done()
+	return 0, r.ctx.Err()
+}
+
+func TestTimeoutTarget_Push(t *testing.T) {
+	ctx := context.Background()
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	src := memory.New()
+	if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := &stuckTarget{Store: memory.New(), stuck: 2}
+	if err := oras.CopyGraph(ctx, src, NewTimeoutTarget(dst, src, 10*time.Millisecond, 2), desc, oras.DefaultCopyGraphOptions); err != nil {
+		t.Fatal("CopyGraph() error =", err)
+	}
+	if got, err := content.FetchAll(ctx, dst.Store, desc); err != nil || !bytes.Equal(got, blob) {
+		t.Errorf("pushed content = %q, error = %v, want %q", got, err, blob)
+	}
+
+	dst = &stuckTarget{Store: memory.New(), stuck: 2}
+	if err := oras.CopyGraph(ctx, src, NewTimeoutTarget(dst, src, 10*time.Millisecond, 1), desc, oras.DefaultCopyGraphOptions); err == nil {
+		t.Error("CopyGraph() error = nil, want error")
+	}
+}
+
+func TestTimeoutReadOnlyTarget_Fetch(t *testing.T) {
+	ctx := context.Background()
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+
+	src := &stuckTarget{Store: memory.New(), stuck: 2}
+	if err := src.Store.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := content.FetchAll(ctx, NewTimeoutReadOnlyTarget(src, 10*time.Millisecond, 2), desc)
+	if err != nil {
+		t.Fatal("FetchAll() error =", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("fetched content = %q, want %q", got, blob)
+	}
+
+	src.calls.Store(0)
+	if _, err := content.FetchAll(ctx, NewTimeoutReadOnlyTarget(src, 10*time.Millisecond, 1), desc); err == nil {
+		t.Error("FetchAll() error = nil, want error")
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package contentutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras/internal/descriptor"
+)
+
+// EnsureGraphExists checks that root and all of its successors exist in
+// storage. All missing nodes are reported in the returned error.
+func EnsureGraphExists(ctx context.Context, storage content.ReadOnlyStorage, root ocispec.Descriptor) error {
+	var missing []string
+	visited := make(map[string]bool)
+	stack := []ocispec.Descriptor{root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[node.Digest.String()] {
+			continue
+		}
+		visited[node.Digest.String()] = true
+
+		exists, err := storage.Exists(ctx, node)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			missing = append(missing, node.Digest.String())
+			continue
+		}
+		successors, err := content.Successors(ctx, storage, node)
+		if err != nil {
+			return err
+		}
+		stack = append(stack, successors...)
+	}
+	if len(missing) != 0 {
+		return fmt.Errorf("%w: missing content %s", errdef.ErrNotFound, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+type verifiedReadOnlyGraphTarget struct {
+	oras.ReadOnlyGraphTarget
+}
+
+// VerifiedReadOnlyGraphTarget returns a ReadOnlyGraphTarget verifying the size
+// and the digest of the fetched content against the requested descriptor.
+// A mismatch is reported by the Read call consuming the end of the content.
+func VerifiedReadOnlyGraphTarget(target oras.ReadOnlyGraphTarget) oras.ReadOnlyGraphTarget {
+	return &verifiedReadOnlyGraphTarget{
+		ReadOnlyGraphTarget: target,
+	}
+}
+
+// Fetch fetches the content identified by the descriptor with verification.
+func (t *verifiedReadOnlyGraphTarget) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	rc, err := t.ReadOnlyGraphTarget.Fetch(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	return NewVerifiedReadCloser(rc, target), nil
+}
+
+type verifiedReadCloser struct {
+	io.Closer
+	desc ocispec.Descriptor
+	vr   *content.VerifyReader
+}
+
+// NewVerifiedReadCloser wraps rc so that reading to the end of the content
+// fails if its size or digest does not match desc.
+func NewVerifiedReadCloser(rc io.ReadCloser, desc ocispec.Descriptor) io.ReadCloser {
+	return &verifiedReadCloser{
+		Closer: rc,
+		desc:   desc,
+		vr:     content.NewVerifyReader(rc, desc),
+	}
+}
+
+// Read reads from the underlying reader and verifies the content at EOF.
+func (r *verifiedReadCloser) Read(p []byte) (int, error) {
+	n, err := r.vr.Read(p)
+	if err == io.EOF {
+		if verifyErr := r.vr.Verify(); verifyErr != nil {
+			return n, fmt.Errorf("failed to verify %s: %w", r.desc.Digest, verifyErr)
+		}
+	}
+	return n, err
+}
+
+// verifiedDiscardTarget is a GraphTarget verifying and discarding the pushed
+// blobs. Manifests are kept in memory so that their successors can be found.
+type verifiedDiscardTarget struct {
+	*memory.Store
+	verified sync.Map // map[digest.Digest]struct{}
+}
+
+// NewVerifiedDiscardTarget returns a GraphTarget verifying the size and the
+// digest of all pushed content without storing the blobs.
+func NewVerifiedDiscardTarget() oras.GraphTarget {
+	return &verifiedDiscardTarget{
+		Store: memory.New(),
+	}
+}
+
+// Push verifies the content against expected. Blobs are discarded after
+// verification.
+func (t *verifiedDiscardTarget) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	if descriptor.IsManifest(expected) {
+		b, err := content.ReadAll(r, expected)
+		if err != nil {
+			return fmt.Errorf("failed to verify %s: %w", expected.Digest, err)
+		}
+		return t.Store.Push(ctx, expected, bytes.NewReader(b))
+	}
+	if _, err := io.Copy(io.Discard, NewVerifiedReadCloser(io.NopCloser(r), expected)); err != nil {
+		return err
+	}
+	t.verified.Store(expected.Digest, struct{}{})
+	return nil
+}
+
+// Exists returns true if the described content has been verified.
+func (t *verifiedDiscardTarget) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	if _, ok := t.verified.Load(target.Digest); ok {
+		return true, nil
+	}
+	return t.Store.Exists(ctx, target)
+}
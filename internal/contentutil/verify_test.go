@@ -0,0 +1,142 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package contentutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func pushBlob(t *testing.T, store *memory.Store, mediaType string, blob []byte) ocispec.Descriptor {
+	t.Helper()
+	desc := content.NewDescriptorFromBytes(mediaType, blob)
+	if err := store.Push(context.Background(), desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("failed to push blob:", err)
+	}
+	return desc
+}
+
+func TestEnsureGraphExists(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	layer := pushBlob(t, store, "test", []byte("layer"))
+	missing := content.NewDescriptorFromBytes("test", []byte("missing"))
+	manifestBytes, err := json.Marshal(ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    pushBlob(t, store, ocispec.MediaTypeEmptyJSON, []byte("{}")),
+		Layers:    []ocispec.Descriptor{layer, missing},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := pushBlob(t, store, ocispec.MediaTypeImageManifest, manifestBytes)
+
+	if err := EnsureGraphExists(ctx, store, layer); err != nil {
+		t.Errorf("EnsureGraphExists() error = %v, want nil", err)
+	}
+	err = EnsureGraphExists(ctx, store, manifest)
+	if !errors.Is(err, errdef.ErrNotFound) {
+		t.Fatalf("EnsureGraphExists() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+	if !strings.Contains(err.Error(), missing.Digest.String()) {
+		t.Errorf("EnsureGraphExists() error = %v, want missing digest %s", err, missing.Digest)
+	}
+}
+
+func TestVerifiedReadOnlyGraphTarget_Fetch(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	blob := []byte("hello world")
+	desc := pushBlob(t, store, "test", blob)
+	target := VerifiedReadOnlyGraphTarget(store)
+
+	// matched content
+	rc, err := target.Fetch(ctx, desc)
+	if err != nil {
+		t.Fatal("Fetch() error =", err)
+	}
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal("ReadAll() error =", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("Fetch() = %v, want %v", got, blob)
+	}
+	_ = rc.Close()
+
+	// mismatched content
+	rc = NewVerifiedReadCloser(io.NopCloser(bytes.NewReader([]byte("hello wOrld"))), desc)
+	if _, err = io.ReadAll(rc); !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("ReadAll() error = %v, want %v", err, content.ErrMismatchedDigest)
+	}
+
+	// truncated content
+	rc = NewVerifiedReadCloser(io.NopCloser(bytes.NewReader(blob[:5])), ocispec.Descriptor{
+		Digest: digest.FromBytes(blob),
+		Size:   int64(len(blob)),
+	})
+	if _, err = io.ReadAll(rc); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("ReadAll() error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestVerifiedDiscardTarget(t *testing.T) {
+	ctx := context.Background()
+	target := NewVerifiedDiscardTarget()
+	blob := []byte("hello world")
+	blobDesc := content.NewDescriptorFromBytes("test", blob)
+	manifestBytes := []byte(`{"layers":[]}`)
+	manifestDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifestBytes)
+
+	// verified content
+	if err := target.Push(ctx, blobDesc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	if err := target.Push(ctx, manifestDesc, bytes.NewReader(manifestBytes)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	for _, desc := range []ocispec.Descriptor{blobDesc, manifestDesc} {
+		if exists, err := target.Exists(ctx, desc); err != nil || !exists {
+			t.Errorf("Exists() = %v, %v, want true, nil", exists, err)
+		}
+	}
+	if _, err := target.Fetch(ctx, manifestDesc); err != nil {
+		t.Errorf("Fetch() error = %v, want nil", err)
+	}
+	if _, err := target.Fetch(ctx, blobDesc); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Fetch() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+
+	// mismatched content
+	mismatched := content.NewDescriptorFromBytes("test", []byte("hello wOrld"))
+	if err := target.Push(ctx, mismatched, bytes.NewReader(blob)); !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("Push() error = %v, want %v", err, content.ErrMismatchedDigest)
+	}
+	if exists, _ := target.Exists(ctx, mismatched); exists {
+		t.Errorf("Exists() = %v, want false", exists)
+	}
+}
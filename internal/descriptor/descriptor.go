@@ -27,6 +27,17 @@ func IsImageManifest(desc ocispec.Descriptor) bool {
 	return desc.MediaType == docker.MediaTypeManifest || desc.MediaType == ocispec.MediaTypeImageManifest
 }
 
+// IsManifest checks whether a descriptor describes a manifest or an index,
+// which may reference other content.
+func IsManifest(desc ocispec.Descriptor) bool {
+	switch desc.MediaType {
+	case docker.MediaTypeManifest, docker.MediaTypeManifestList,
+		ocispec.MediaTypeImageManifest, ocispec.MediaTypeImageIndex:
+		return true
+	}
+	return false
+}
+
 // ShortDigest converts the digest of the descriptor to a short form for displaying.
 func ShortDigest(desc ocispec.Descriptor) (digestString string) {
 	digestString = desc.Digest.String()
@@ -56,6 +56,23 @@ func TestDescriptor_IsImageManifest(t *testing.T) {
 	}
 }
 
+func TestDescriptor_IsManifest(t *testing.T) {
+	got := descriptor.IsManifest(imageDesc)
+	if !reflect.DeepEqual(got, true) {
+		t.Fatalf("IsManifest() got %v, want %v", got, true)
+	}
+
+	got = descriptor.IsManifest(ocispec.Descriptor{MediaType: ocispec.MediaTypeImageIndex})
+	if !reflect.DeepEqual(got, true) {
+		t.Fatalf("IsManifest() got %v, want %v", got, true)
+	}
+
+	got = descriptor.IsManifest(ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayer})
+	if !reflect.DeepEqual(got, false) {
+		t.Fatalf("IsManifest() got %v, want %v", got, false)
+	}
+}
+
 func TestDescriptor_ShortDigest(t *testing.T) {
 	expected := "2e0e0fe1fb3e"
 	got := descriptor.ShortDigest(titledDesc)
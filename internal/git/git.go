@@ -0,0 +1,83 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package git reads the metadata of Git working trees via the git executable.
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrNotRepository is returned when a path is not in a Git working tree.
+var ErrNotRepository = errors.New("not a git repository")
+
+// Metadata describes the checked out state of a Git working tree.
+type Metadata struct {
+	// Revision is the commit hash of HEAD.
+	Revision string
+	// Branch is the name of the checked out branch, empty for a detached HEAD.
+	Branch string
+	// Source is the URL of the remote "origin", empty if not configured.
+	Source string
+	// Dirty is true if the working tree has uncommitted changes.
+	Dirty bool
+}
+
+// ReadMetadata reads the metadata of the Git working tree at path.
+func ReadMetadata(ctx context.Context, path string) (Metadata, error) {
+	inside, err := run(ctx, path, "rev-parse", "--is-inside-work-tree")
+	if err != nil || inside != "true" {
+		if errors.Is(err, exec.ErrNotFound) {
+			return Metadata{}, err
+		}
+		return Metadata{}, fmt.Errorf("%s: %w", path, ErrNotRepository)
+	}
+	var metadata Metadata
+	if metadata.Revision, err = run(ctx, path, "rev-parse", "HEAD"); err != nil {
+		return Metadata{}, err
+	}
+	if branch, err := run(ctx, path, "symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		metadata.Branch = branch
+	}
+	if source, err := run(ctx, path, "remote", "get-url", "origin"); err == nil {
+		metadata.Source = source
+	}
+	status, err := run(ctx, path, "status", "--porcelain")
+	if err != nil {
+		return Metadata{}, err
+	}
+	metadata.Dirty = status != ""
+	return metadata, nil
+}
+
+// run runs the git command with args in dir and returns the trimmed output.
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
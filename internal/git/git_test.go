@@ -0,0 +1,74 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadMetadata(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--quiet", "--initial-branch", "main"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--allow-empty", "-m", "init"},
+		{"remote", "add", "origin", "https://example.com/repo.git"},
+	} {
+		if _, err := run(ctx, dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	revision, err := run(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadMetadata(ctx, dir)
+	if err != nil {
+		t.Fatal("ReadMetadata() error =", err)
+	}
+	want := Metadata{
+		Revision: revision,
+		Branch:   "main",
+		Source:   "https://example.com/repo.git",
+	}
+	if got != want {
+		t.Errorf("ReadMetadata() = %+v, want %+v", got, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, err = ReadMetadata(ctx, dir)
+	if err != nil {
+		t.Fatal("ReadMetadata() error =", err)
+	}
+	if !got.Dirty {
+		t.Error("ReadMetadata() Dirty = false, want true")
+	}
+
+	if _, err := ReadMetadata(ctx, t.TempDir()); !errors.Is(err, ErrNotRepository) {
+		t.Errorf("ReadMetadata() error = %v, want %v", err, ErrNotRepository)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package net
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimitedTransport is an http.RoundTripper that spaces out the requests
+// so that no more than a given number of requests are sent per second.
+type RateLimitedTransport struct {
+	http.RoundTripper
+	interval time.Duration
+
+	lock sync.Mutex
+	next time.Time
+}
+
+// NewRateLimitedTransport returns a transport sending at most
+// requestsPerSecond requests per second through base.
+func NewRateLimitedTransport(base http.RoundTripper, requestsPerSecond float64) *RateLimitedTransport {
+	return &RateLimitedTransport{
+		RoundTripper: base,
+		interval:     time.Duration(float64(time.Second) / requestsPerSecond),
+	}
+}
+
+// RoundTrip waits for the next available slot before sending the request.
+func (t *RateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if wait := t.reserve(); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	return t.RoundTripper.RoundTrip(req)
+}
+
+// reserve reserves the next slot and returns the duration to wait for it.
+func (t *RateLimitedTransport) reserve() time.Duration {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	wait := t.next.Sub(now)
+	t.next = t.next.Add(t.interval)
+	return wait
+}
@@ -0,0 +1,68 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package net
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimitedTransport_RoundTrip(t *testing.T) {
+	var count atomic.Int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count.Add(1)
+	}))
+	defer ts.Close()
+
+	client := &http.Client{
+		Transport: NewRateLimitedTransport(http.DefaultTransport, 20),
+	}
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		resp, err := client.Get(ts.URL)
+		if err != nil {
+			t.Fatal("Get() error =", err)
+		}
+		resp.Body.Close()
+	}
+	// the first request is sent immediately, the following ones wait 50ms each
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("5 requests at 20 requests per second took %v, want at least 200ms", elapsed)
+	}
+	if got := count.Load(); got != 5 {
+		t.Errorf("server received %d requests, want 5", got)
+	}
+}
+
+func TestRateLimitedTransport_RoundTrip_canceled(t *testing.T) {
+	transport := NewRateLimitedTransport(http.DefaultTransport, 0.1)
+	_ = transport.reserve()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := transport.RoundTrip(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("RoundTrip() error = %v, want %v", err, context.Canceled)
+	}
+}
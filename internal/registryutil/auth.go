@@ -24,7 +24,10 @@ import (
 
 // WithScopeHint adds a hinted scope to the context.
 func WithScopeHint(ctx context.Context, target any, actions ...string) context.Context {
-	if repo, ok := target.(*remote.Repository); ok {
+	switch repo := target.(type) {
+	case *remote.Repository:
+		return auth.AppendRepositoryScope(ctx, repo.Reference, actions...)
+	case *ChunkedRepository:
 		return auth.AppendRepositoryScope(ctx, repo.Reference, actions...)
 	}
 	return ctx
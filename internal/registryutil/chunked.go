@@ -0,0 +1,241 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registryutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras/internal/descriptor"
+)
+
+// maxChunkResumes is the maximum number of times the upload of a chunk is
+// resumed after a failure.
+const maxChunkResumes = 3
+
+// ChunkedRepository is a remote repository uploading the blobs larger than
+// ChunkSize in chunks, via the chunked upload flow of the distribution spec.
+// The upload of a chunk is resumed from the offset committed by the registry
+// if it fails. If Sessions is set, the uploads interrupted by a previous push
+// are resumed from the offset committed by the registry.
+type ChunkedRepository struct {
+	*remote.Repository
+	ChunkSize int64
+	Sessions  *UploadSessions
+}
+
+// Push pushes the content, in chunks if it is a blob larger than ChunkSize.
+func (r *ChunkedRepository) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	if expected.Size <= r.ChunkSize || descriptor.IsManifest(expected) {
+		return r.Repository.Push(ctx, expected, content)
+	}
+	return r.pushChunked(ctx, expected, content)
+}
+
+func (r *ChunkedRepository) pushChunked(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	client := r.Client
+	if client == nil {
+		client = auth.DefaultClient
+	}
+	scheme := "https"
+	if r.PlainHTTP {
+		scheme = "http"
+	}
+	key := r.Reference.Host() + "/" + r.Reference.Repository + "@" + expected.Digest.String()
+	location, offset := r.resumeSession(ctx, client, key, expected.Size)
+	if location == nil {
+		startURL := fmt.Sprintf("%s://%s/v2/%s/blobs/uploads/", scheme, r.Reference.Host(), r.Reference.Repository)
+		resp, err := do(ctx, client, http.MethodPost, startURL, nil, nil, http.StatusAccepted)
+		if err != nil {
+			return fmt.Errorf("failed to start the upload of %s: %w", expected.Digest, err)
+		}
+		if location, err = uploadLocation(resp); err != nil {
+			return err
+		}
+	} else if _, err := io.CopyN(io.Discard, content, offset); err != nil {
+		return fmt.Errorf("failed to read %s: %w", expected.Digest, err)
+	}
+	if err := r.recordSession(key, location); err != nil {
+		return err
+	}
+
+	buf := make([]byte, r.ChunkSize)
+	for offset < expected.Size {
+		n, err := io.ReadFull(content, buf[:min(r.ChunkSize, expected.Size-offset)])
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", expected.Digest, err)
+		}
+		chunk := buf[:n]
+		if location, err = uploadChunk(ctx, client, location, offset, chunk); err != nil {
+			return fmt.Errorf("failed to upload %s: %w", expected.Digest, err)
+		}
+		offset += int64(n)
+		if err := r.recordSession(key, location); err != nil {
+			return err
+		}
+	}
+
+	query := location.Query()
+	query.Set("digest", expected.Digest.String())
+	location.RawQuery = query.Encode()
+	if _, err := do(ctx, client, http.MethodPut, location.String(), nil, nil, http.StatusCreated); err != nil {
+		return fmt.Errorf("failed to complete the upload of %s: %w", expected.Digest, err)
+	}
+	return r.recordSession(key, nil)
+}
+
+// resumeSession returns the location of the upload of key recorded in
+// Sessions and the offset committed by the registry, or nil if there is no
+// upload to resume.
+func (r *ChunkedRepository) resumeSession(ctx context.Context, client remote.Client, key string, size int64) (*url.URL, int64) {
+	if r.Sessions == nil {
+		return nil, 0
+	}
+	recorded := r.Sessions.location(key)
+	if recorded == "" {
+		return nil, 0
+	}
+	// the session may have expired or been cancelled by the registry
+	resp, err := do(ctx, client, http.MethodGet, recorded, nil, nil, http.StatusNoContent)
+	if err != nil {
+		return nil, 0
+	}
+	committed, err := committedSize(resp.Header.Get("Range"))
+	if err != nil || committed > size {
+		return nil, 0
+	}
+	location, err := uploadLocation(resp)
+	if err != nil {
+		return nil, 0
+	}
+	return location, committed
+}
+
+// recordSession records the location of the upload of key in Sessions, or
+// removes the upload if location is nil.
+func (r *ChunkedRepository) recordSession(key string, location *url.URL) error {
+	if r.Sessions == nil {
+		return nil
+	}
+	var value string
+	if location != nil {
+		value = location.String()
+	}
+	if err := r.Sessions.record(key, value); err != nil {
+		return fmt.Errorf("failed to record the upload session: %w", err)
+	}
+	return nil
+}
+
+// uploadChunk uploads chunk starting at offset to location, resuming from the
+// committed offset on failures. The location of the next chunk is returned.
+func uploadChunk(ctx context.Context, client remote.Client, location *url.URL, offset int64, chunk []byte) (*url.URL, error) {
+	sent := int64(0)
+	for resumes := 0; ; resumes++ {
+		header := http.Header{
+			"Content-Type":  {"application/octet-stream"},
+			"Content-Range": {fmt.Sprintf("%d-%d", offset+sent, offset+int64(len(chunk))-1)},
+		}
+		resp, err := do(ctx, client, http.MethodPatch, location.String(), header, chunk[sent:], http.StatusAccepted)
+		if err == nil {
+			return uploadLocation(resp)
+		}
+		if resumes == maxChunkResumes || ctx.Err() != nil {
+			return nil, err
+		}
+		// query the committed offset to resume from
+		resp, statusErr := do(ctx, client, http.MethodGet, location.String(), nil, nil, http.StatusNoContent)
+		if statusErr != nil {
+			return nil, fmt.Errorf("%w; failed to query the upload status: %v", err, statusErr)
+		}
+		committed, rangeErr := committedSize(resp.Header.Get("Range"))
+		if rangeErr != nil || committed < offset || committed > offset+int64(len(chunk)) {
+			return nil, fmt.Errorf("%w; cannot resume from the upload range %q", err, resp.Header.Get("Range"))
+		}
+		if location, err = uploadLocation(resp); err != nil {
+			return nil, err
+		}
+		sent = committed - offset
+		if sent == int64(len(chunk)) {
+			return location, nil
+		}
+	}
+}
+
+// committedSize parses the size committed from the Range header of an upload
+// in the format of "0-<end>".
+func committedSize(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	_, end, ok := strings.Cut(value, "-")
+	if !ok {
+		return 0, fmt.Errorf("invalid range %q", value)
+	}
+	last, err := strconv.ParseInt(end, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid range %q: %w", value, err)
+	}
+	return last + 1, nil
+}
+
+// uploadLocation returns the absolute location of the upload session.
+func uploadLocation(resp *http.Response) (*url.URL, error) {
+	value := resp.Header.Get("Location")
+	if value == "" {
+		return nil, fmt.Errorf("%s %q: missing Location header", resp.Request.Method, resp.Request.URL)
+	}
+	location, err := resp.Request.URL.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s %q: invalid Location header: %w", resp.Request.Method, resp.Request.URL, err)
+	}
+	return location, nil
+}
+
+// do sends the request and checks the response status. The response body is
+// always drained and closed.
+func do(ctx context.Context, client remote.Client, method, url string, header http.Header, body []byte, expectedStatus int) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, r)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != expectedStatus {
+		return nil, fmt.Errorf("%s %q: unexpected status %s", method, url, resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,209 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registryutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// chunkedRegistry is a registry accepting chunked uploads. The first PATCH
+// request containing failAt is interrupted after committing the content
+// before failAt.
+type chunkedRegistry struct {
+	lock      sync.Mutex
+	uploaded  bytes.Buffer
+	failAt    int
+	failed    bool
+	completed digest.Digest
+	patches   int
+}
+
+func (reg *chunkedRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reg.lock.Lock()
+	defer reg.lock.Unlock()
+	setRange := func() {
+		w.Header().Set("Location", "/v2/test/blobs/uploads/session?state="+strconv.Itoa(reg.uploaded.Len()))
+		w.Header().Set("Range", fmt.Sprintf("0-%d", reg.uploaded.Len()-1))
+	}
+	switch r.Method {
+	case http.MethodPost:
+		setRange()
+		w.WriteHeader(http.StatusAccepted)
+	case http.MethodPatch:
+		reg.patches++
+		var start, end int
+		if _, err := fmt.Sscanf(r.Header.Get("Content-Range"), "%d-%d", &start, &end); err != nil || start != reg.uploaded.Len() {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !reg.failed && start <= reg.failAt && reg.failAt <= end {
+			reg.failed = true
+			reg.uploaded.Write(body[:reg.failAt-start])
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		reg.uploaded.Write(body)
+		setRange()
+		w.WriteHeader(http.StatusAccepted)
+	case http.MethodGet:
+		setRange()
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodPut:
+		reg.completed = digest.Digest(r.URL.Query().Get("digest"))
+		if reg.completed != digest.FromBytes(reg.uploaded.Bytes()) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestChunkedRepository_Push(t *testing.T) {
+	blob := []byte(strings.Repeat("0123456789", 10))
+	desc := content.NewDescriptorFromBytes("application/octet-stream", blob)
+	reg := &chunkedRegistry{failAt: 45}
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := remote.NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.PlainHTTP = true
+	repo.Client = http.DefaultClient
+
+	chunked := &ChunkedRepository{Repository: repo, ChunkSize: 30}
+	if err := chunked.Push(context.Background(), desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	if !bytes.Equal(reg.uploaded.Bytes(), blob) {
+		t.Errorf("uploaded = %q, want %q", reg.uploaded.Bytes(), blob)
+	}
+	if reg.completed != desc.Digest {
+		t.Errorf("completed digest = %v, want %v", reg.completed, desc.Digest)
+	}
+	// 4 chunks, one of them resumed
+	if want := 5; reg.patches != want {
+		t.Errorf("PATCH requests = %d, want %d", reg.patches, want)
+	}
+}
+
+// errorReader is a reader failing with err once content is read.
+type errorReader struct {
+	content io.Reader
+	err     error
+}
+
+func (r *errorReader) Read(p []byte) (int, error) {
+	n, err := r.content.Read(p)
+	if err == io.EOF {
+		err = r.err
+	}
+	return n, err
+}
+
+func TestChunkedRepository_Push_sessions(t *testing.T) {
+	blob := []byte(strings.Repeat("0123456789", 10))
+	desc := content.NewDescriptorFromBytes("application/octet-stream", blob)
+	reg := &chunkedRegistry{failAt: len(blob)}
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionPath := filepath.Join(t.TempDir(), "sessions.json")
+	newRepository := func() *ChunkedRepository {
+		repo, err := remote.NewRepository(uri.Host + "/test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		repo.PlainHTTP = true
+		repo.Client = http.DefaultClient
+		sessions, err := LoadUploadSessions(sessionPath)
+		if err != nil {
+			t.Fatal("LoadUploadSessions() error =", err)
+		}
+		return &ChunkedRepository{Repository: repo, ChunkSize: 30, Sessions: sessions}
+	}
+
+	// interrupt the push after the first chunk
+	interrupted := errors.New("interrupted")
+	if err := newRepository().Push(context.Background(), desc, &errorReader{content: bytes.NewReader(blob[:40]), err: interrupted}); !errors.Is(err, interrupted) {
+		t.Fatalf("Push() error = %v, want %v", err, interrupted)
+	}
+	// the registry rejects the chunks of a new upload starting at 0
+	if err := newRepository().Push(context.Background(), desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	if reg.completed != desc.Digest {
+		t.Errorf("completed digest = %v, want %v", reg.completed, desc.Digest)
+	}
+	if want := 4; reg.patches != want {
+		t.Errorf("PATCH requests = %d, want %d", reg.patches, want)
+	}
+	sessions, err := LoadUploadSessions(sessionPath)
+	if err != nil {
+		t.Fatal("LoadUploadSessions() error =", err)
+	}
+	if len(sessions.locations) != 0 {
+		t.Errorf("completed upload sessions are not removed: %v", sessions.locations)
+	}
+}
+
+func Test_committedSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "", want: 0},
+		{value: "0-9", want: 10},
+		{value: "0-", wantErr: true},
+		{value: "10", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := committedSize(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("committedSize(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("committedSize(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
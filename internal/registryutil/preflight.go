@@ -0,0 +1,133 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registryutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// Capabilities of a registry that can be required before uploading content.
+// The accepted manifest media types are not probed since the distribution API
+// offers no way to check them without pushing a manifest.
+const (
+	// CapabilityReferrersAPI requires the referrers API.
+	CapabilityReferrersAPI = "referrers-api"
+	// CapabilityChunkedUpload requires the chunked blob upload flow.
+	CapabilityChunkedUpload = "chunked-upload"
+)
+
+// Capabilities lists all the supported capabilities.
+var Capabilities = []string{CapabilityReferrersAPI, CapabilityChunkedUpload}
+
+// CheckCapabilities probes the registry of repo and returns an error listing
+// all the capabilities the registry does not have. The registry is always
+// checked to support the distribution API.
+func CheckCapabilities(ctx context.Context, repo *remote.Repository, capabilities []string) error {
+	client := repo.Client
+	if client == nil {
+		client = auth.DefaultClient
+	}
+	scheme := "https"
+	if repo.PlainHTTP {
+		scheme = "http"
+	}
+	base := fmt.Sprintf("%s://%s/v2/", scheme, repo.Reference.Host())
+
+	if err := probe(ctx, client, base, nil); err != nil {
+		return fmt.Errorf("registry %s does not support the distribution API: %w", repo.Reference.Host(), err)
+	}
+	var unmet []string
+	for _, capability := range capabilities {
+		var err error
+		switch capability {
+		case CapabilityReferrersAPI:
+			url := fmt.Sprintf("%s%s/referrers/%s", base, repo.Reference.Repository, digest.FromBytes(nil))
+			err = probe(ctx, client, url, func(resp *http.Response) error {
+				mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+				if mediaType != ocispec.MediaTypeImageIndex {
+					return fmt.Errorf("unexpected content type %q", mediaType)
+				}
+				return nil
+			})
+		case CapabilityChunkedUpload:
+			err = probeChunkedUpload(ctx, client, base+repo.Reference.Repository+"/blobs/uploads/")
+		default:
+			err = errors.New("unknown capability")
+		}
+		if err != nil {
+			unmet = append(unmet, fmt.Sprintf("%s (%v)", capability, err))
+		}
+	}
+	if len(unmet) != 0 {
+		return fmt.Errorf("registry %s does not meet the requirements: %s", repo.Reference.Host(), strings.Join(unmet, ", "))
+	}
+	return nil
+}
+
+// probe sends a GET request to url and checks the response is successful.
+func probe(ctx context.Context, client remote.Client, url string, check func(*http.Response) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %s", req.Method, req.URL, resp.Status)
+	}
+	if check != nil {
+		return check(resp)
+	}
+	return nil
+}
+
+// probeChunkedUpload starts an upload session at uploadURL, uploads a one-byte
+// chunk and cancels the session so that no blob is committed.
+func probeChunkedUpload(ctx context.Context, client remote.Client, uploadURL string) error {
+	resp, err := do(ctx, client, http.MethodPost, uploadURL, nil, nil, http.StatusAccepted)
+	if err != nil {
+		return err
+	}
+	location, err := uploadLocation(resp)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// the registry expires the session anyway if the cancellation fails
+		_, _ = do(ctx, client, http.MethodDelete, location.String(), nil, nil, http.StatusNoContent)
+	}()
+	header := http.Header{
+		"Content-Type":  {"application/octet-stream"},
+		"Content-Range": {"0-0"},
+	}
+	_, err = do(ctx, client, http.MethodPatch, location.String(), header, []byte{0}, http.StatusAccepted)
+	return err
+}
@@ -0,0 +1,88 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registryutil
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+func TestCheckCapabilities(t *testing.T) {
+	referrersSupported := true
+	chunkedSupported := true
+	cancelled := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/":
+		case strings.HasPrefix(r.URL.Path, "/v2/test/referrers/") && referrersSupported:
+			w.Header().Set("Content-Type", ocispec.MediaTypeImageIndex)
+			_, _ = w.Write([]byte(`{"schemaVersion":2,"manifests":[]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/test/blobs/uploads/":
+			w.Header().Set("Location", "/v2/test/blobs/uploads/session")
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodPatch && r.URL.Path == "/v2/test/blobs/uploads/session" && chunkedSupported:
+			w.Header().Set("Location", "/v2/test/blobs/uploads/session")
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodDelete && r.URL.Path == "/v2/test/blobs/uploads/session":
+			cancelled = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := remote.NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.PlainHTTP = true
+	ctx := context.Background()
+
+	if err := CheckCapabilities(ctx, repo, Capabilities); err != nil {
+		t.Error("CheckCapabilities() error =", err)
+	}
+	if !cancelled {
+		t.Error("the upload session of the chunked upload probe is not cancelled")
+	}
+
+	referrersSupported = false
+	chunkedSupported = false
+	err = CheckCapabilities(ctx, repo, Capabilities)
+	if err == nil {
+		t.Fatal("CheckCapabilities() error = nil, want error")
+	}
+	for _, capability := range Capabilities {
+		if !strings.Contains(err.Error(), capability) {
+			t.Errorf("CheckCapabilities() error = %v, want %q listed", err, capability)
+		}
+	}
+
+	repo.Reference.Registry = "localhost:1"
+	if err := CheckCapabilities(ctx, repo, nil); err == nil {
+		t.Error("CheckCapabilities() error = nil, want error for unreachable registry")
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registryutil
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// UploadSessions records the locations of the chunked uploads in progress in
+// a file, so that the uploads interrupted by a previous push are resumed.
+type UploadSessions struct {
+	path      string
+	lock      sync.Mutex
+	locations map[string]string
+}
+
+// LoadUploadSessions loads the upload sessions recorded in the file at path,
+// which is created on the first recorded session if not existing.
+func LoadUploadSessions(path string) (*UploadSessions, error) {
+	s := &UploadSessions{
+		path:      path,
+		locations: make(map[string]string),
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if len(data) == 0 {
+		return s, nil
+	}
+	if err := json.Unmarshal(data, &s.locations); err != nil {
+		return nil, &fs.PathError{Op: "parse", Path: path, Err: err}
+	}
+	return s, nil
+}
+
+// location returns the recorded location of the upload of key.
+func (s *UploadSessions) location(key string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.locations[key]
+}
+
+// record records the location of the upload of key, or removes the upload if
+// location is empty, and saves the sessions to the file.
+func (s *UploadSessions) record(key, location string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if location == "" {
+		if _, ok := s.locations[key]; !ok {
+			return nil
+		}
+		delete(s.locations, key)
+	} else {
+		s.locations[key] = location
+	}
+	data, err := json.MarshalIndent(s.locations, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
@@ -0,0 +1,241 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package speed measures the throughput of copied content and the latency of
+// the HTTP requests sent while copying.
+package speed
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras/internal/descriptor"
+)
+
+// Recorder records the time spent on copying each node and the latency of
+// each HTTP request. A Recorder is safe for concurrent use.
+type Recorder struct {
+	lock     sync.Mutex
+	created  time.Time
+	started  map[string]time.Time
+	nodes    []node
+	skipped  int64
+	requests map[string][]time.Duration
+	now      func() time.Time
+}
+
+// Summary summarizes the blobs copied since a Recorder is created.
+type Summary struct {
+	// CopiedBytes is the total size of the copied blobs.
+	CopiedBytes int64
+	// SkippedBytes is the total size of the blobs skipped since they exist
+	// in the destination.
+	SkippedBytes int64
+	// Elapsed is the time elapsed since the Recorder is created.
+	Elapsed time.Duration
+}
+
+// BytesPerSecond returns the effective throughput, the copied bytes over the
+// elapsed time.
+func (s Summary) BytesPerSecond() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.CopiedBytes) / s.Elapsed.Seconds()
+}
+
+// node is a copied node with the time span of copying it.
+type node struct {
+	desc       ocispec.Descriptor
+	start, end time.Time
+}
+
+// NewRecorder creates a new Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		created:  time.Now(),
+		started:  make(map[string]time.Time),
+		requests: make(map[string][]time.Duration),
+		now:      time.Now,
+	}
+}
+
+// UpdateCopyOptions wraps the PreCopy and PostCopy hooks of opts to time each
+// copied node.
+func (r *Recorder) UpdateCopyOptions(opts *oras.CopyGraphOptions) {
+	preCopy := opts.PreCopy
+	opts.PreCopy = func(ctx context.Context, desc ocispec.Descriptor) error {
+		r.start(desc)
+		if preCopy != nil {
+			return preCopy(ctx, desc)
+		}
+		return nil
+	}
+	onCopySkipped := opts.OnCopySkipped
+	opts.OnCopySkipped = func(ctx context.Context, desc ocispec.Descriptor) error {
+		r.skip(desc)
+		if onCopySkipped != nil {
+			return onCopySkipped(ctx, desc)
+		}
+		return nil
+	}
+	postCopy := opts.PostCopy
+	opts.PostCopy = func(ctx context.Context, desc ocispec.Descriptor) error {
+		r.done(desc)
+		if postCopy != nil {
+			return postCopy(ctx, desc)
+		}
+		return nil
+	}
+}
+
+func (r *Recorder) start(desc ocispec.Descriptor) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.started[desc.Digest.String()] = r.now()
+}
+
+func (r *Recorder) done(desc ocispec.Descriptor) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	start, ok := r.started[desc.Digest.String()]
+	if !ok {
+		return
+	}
+	delete(r.started, desc.Digest.String())
+	r.nodes = append(r.nodes, node{desc: desc, start: start, end: r.now()})
+}
+
+func (r *Recorder) skip(desc ocispec.Descriptor) {
+	if descriptor.IsManifest(desc) {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.skipped += desc.Size
+}
+
+// Summary returns the summary of the blobs copied so far. Manifests are not
+// counted.
+func (r *Recorder) Summary() Summary {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	summary := Summary{
+		SkippedBytes: r.skipped,
+		Elapsed:      r.now().Sub(r.created),
+	}
+	for _, n := range r.nodes {
+		if !descriptor.IsManifest(n.desc) {
+			summary.CopiedBytes += n.desc.Size
+		}
+	}
+	return summary
+}
+
+// Transport returns an http.RoundTripper recording the latency of each
+// request sent through base, until the response header is received.
+func (r *Recorder) Transport(base http.RoundTripper) http.RoundTripper {
+	return &transport{RoundTripper: base, recorder: r}
+}
+
+type transport struct {
+	http.RoundTripper
+	recorder *Recorder
+}
+
+// RoundTrip calls base roundtrip while recording its latency.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := t.recorder.now()
+	resp, err := t.RoundTripper.RoundTrip(req)
+	latency := t.recorder.now().Sub(start)
+
+	t.recorder.lock.Lock()
+	defer t.recorder.lock.Unlock()
+	t.recorder.requests[req.Method] = append(t.recorder.requests[req.Method], latency)
+	return resp, err
+}
+
+// Report writes the throughput of each copied blob, the aggregated
+// throughput and the request latencies to w. The aggregated throughput is
+// computed over the wall-clock time from the first blob copy started to the
+// last one finished, so that concurrent copies are not counted twice.
+func (r *Recorder) Report(w io.Writer) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var size int64
+	var first, last time.Time
+	for _, n := range r.nodes {
+		if descriptor.IsManifest(n.desc) {
+			continue
+		}
+		duration := n.end.Sub(n.start)
+		if _, err := fmt.Fprintf(w, "Blob %s: %d bytes in %v (%s)\n", descriptor.ShortDigest(n.desc), n.desc.Size, duration.Round(time.Millisecond), throughput(n.desc.Size, duration)); err != nil {
+			return err
+		}
+		size += n.desc.Size
+		if first.IsZero() || n.start.Before(first) {
+			first = n.start
+		}
+		if n.end.After(last) {
+			last = n.end
+		}
+	}
+	elapsed := last.Sub(first)
+	if _, err := fmt.Fprintf(w, "Total: %d bytes in %v (%s)\n", size, elapsed.Round(time.Millisecond), throughput(size, elapsed)); err != nil {
+		return err
+	}
+	if r.skipped > 0 {
+		if _, err := fmt.Fprintf(w, "Skipped: %d bytes already existing\n", r.skipped); err != nil {
+			return err
+		}
+	}
+
+	methods := make([]string, 0, len(r.requests))
+	for method := range r.requests {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	for _, method := range methods {
+		latencies := r.requests[method]
+		var sum, max time.Duration
+		for _, l := range latencies {
+			sum += l
+			if l > max {
+				max = l
+			}
+		}
+		avg := sum / time.Duration(len(latencies))
+		if _, err := fmt.Fprintf(w, "Requests %s: %d, average latency %v, max latency %v\n", method, len(latencies), avg.Round(time.Millisecond), max.Round(time.Millisecond)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// throughput formats the throughput of transferring size bytes in d in MB/s.
+func throughput(size int64, d time.Duration) string {
+	if d <= 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.2f MB/s", float64(size)/1e6/d.Seconds())
+}
@@ -0,0 +1,136 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package speed
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+)
+
+func TestRecorder_Report(t *testing.T) {
+	ctx := context.Background()
+	r := NewRecorder()
+	clock := time.Unix(0, 0)
+	r.now = func() time.Time {
+		return clock
+	}
+	var opts oras.CopyGraphOptions
+	var postCopied int
+	opts.PostCopy = func(context.Context, ocispec.Descriptor) error {
+		postCopied++
+		return nil
+	}
+	r.UpdateCopyOptions(&opts)
+
+	blob1 := content.NewDescriptorFromBytes("test", make([]byte, 2_000_000))
+	blob2 := content.NewDescriptorFromBytes("test", make([]byte, 1_000_000+1))
+	manifest := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, []byte("{}"))
+	// blob1 and blob2 are copied concurrently
+	_ = opts.PreCopy(ctx, blob1)
+	clock = clock.Add(500 * time.Millisecond)
+	_ = opts.PreCopy(ctx, blob2)
+	clock = clock.Add(500 * time.Millisecond)
+	_ = opts.PostCopy(ctx, blob1)
+	clock = clock.Add(500 * time.Millisecond)
+	_ = opts.PostCopy(ctx, blob2)
+	_ = opts.PreCopy(ctx, manifest)
+	_ = opts.PostCopy(ctx, manifest)
+	if postCopied != 3 {
+		t.Errorf("wrapped PostCopy called %d times, want 3", postCopied)
+	}
+
+	var buf bytes.Buffer
+	if err := r.Report(&buf); err != nil {
+		t.Fatal("Report() error =", err)
+	}
+	want := "Blob " + blob1.Digest.Encoded()[:12] + ": 2000000 bytes in 1s (2.00 MB/s)\n" +
+		"Blob " + blob2.Digest.Encoded()[:12] + ": 1000001 bytes in 1s (1.00 MB/s)\n" +
+		"Total: 3000001 bytes in 1.5s (2.00 MB/s)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Report() = %q, want %q", got, want)
+	}
+}
+
+func TestRecorder_Transport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	r := NewRecorder()
+	client := &http.Client{Transport: r.Transport(http.DefaultTransport)}
+	for _, method := range []string{http.MethodHead, http.MethodPut, http.MethodHead} {
+		req, err := http.NewRequest(method, ts.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal("Do() error =", err)
+		}
+		resp.Body.Close()
+	}
+	if got := len(r.requests[http.MethodHead]); got != 2 {
+		t.Errorf("recorded %d HEAD requests, want 2", got)
+	}
+	if got := len(r.requests[http.MethodPut]); got != 1 {
+		t.Errorf("recorded %d PUT requests, want 1", got)
+	}
+}
+
+func TestRecorder_Summary(t *testing.T) {
+	ctx := context.Background()
+	r := NewRecorder()
+	clock := time.Unix(0, 0)
+	r.created = clock
+	r.now = func() time.Time {
+		return clock
+	}
+	var opts oras.CopyGraphOptions
+	r.UpdateCopyOptions(&opts)
+	copied := content.NewDescriptorFromBytes("test", make([]byte, 2_000_000))
+	skipped := content.NewDescriptorFromBytes("test", make([]byte, 1_000))
+	manifest := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, []byte("{}"))
+	_ = opts.PreCopy(ctx, copied)
+	_ = opts.OnCopySkipped(ctx, skipped)
+	clock = clock.Add(2 * time.Second)
+	_ = opts.PostCopy(ctx, copied)
+	_ = opts.PreCopy(ctx, manifest)
+	_ = opts.PostCopy(ctx, manifest)
+
+	got := r.Summary()
+	want := Summary{CopiedBytes: 2_000_000, SkippedBytes: 1_000, Elapsed: 2 * time.Second}
+	if got != want {
+		t.Errorf("Summary() = %+v, want %+v", got, want)
+	}
+	if got.BytesPerSecond() != 1_000_000 {
+		t.Errorf("BytesPerSecond() = %v, want 1000000", got.BytesPerSecond())
+	}
+	var buf bytes.Buffer
+	if err := r.Report(&buf); err != nil {
+		t.Fatal("Report() error =", err)
+	}
+	if !strings.Contains(buf.String(), "Skipped: 1000 bytes already existing\n") {
+		t.Errorf("Report() = %q, want the skipped bytes", buf.String())
+	}
+}